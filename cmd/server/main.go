@@ -11,13 +11,18 @@ import (
 	"time"
 
 	"mygoframe/internal/models"
+	"mygoframe/internal/services"
 	"mygoframe/internal/task"
+	"mygoframe/pkg/authz"
 	"mygoframe/pkg/cache"
 	"mygoframe/pkg/config"
 	"mygoframe/pkg/database"
 	"mygoframe/pkg/logger"
+	"mygoframe/pkg/notify"
 	"mygoframe/pkg/queue"
+	"mygoframe/pkg/storage"
 	"mygoframe/routes"
+	"mygoframe/routes/middleware"
 
 	"go.uber.org/zap"
 )
@@ -38,6 +43,16 @@ func Run() {
 		logger.Error("初始化缓存失败", zap.Error(err))
 	}
 
+	// 初始化邮件与短信发送驱动
+	if err := notify.Init(cfg); err != nil {
+		log.Fatalf("初始化消息发送服务失败: %v", err)
+	}
+
+	// 初始化文件存储
+	if err := storage.Init(cfg); err != nil {
+		log.Fatalf("初始化文件存储失败: %v", err)
+	}
+
 	db, err := database.InitDB(cfg)
 	if err != nil {
 		log.Fatalf("初始化数据库失败: %v", err)
@@ -45,16 +60,30 @@ func Run() {
 
 	// 自动迁移
 	if !cfg.System.DisableAutoMigrate {
-		if err := db.AutoMigrate(&models.News{}, &models.User{}); err != nil {
+		if err := db.AutoMigrate(&models.News{}, &models.User{}, &models.Role{}, &models.Permission{}, &models.APIKey{}, &models.RecoveryCode{}, &models.Session{}, &models.AuditEvent{}); err != nil {
 			log.Fatalf("数据库迁移失败: %v", err)
 		}
 	}
 
+	// 初始化安全审计，认证中间件拒绝令牌时同样记录审计事件
+	services.InitAudit(db)
+	middleware.SetAuditRecorder(services.RecordAudit)
+
+	// 加载授权策略，策略文件修改后自动重新加载
+	if err := authz.Init(cfg.Authz); err != nil {
+		log.Fatalf("加载授权策略失败: %v", err)
+	}
+
+	// 初始化内置角色
+	if err := services.NewRoleService(db).EnsureDefaultRoles(context.Background()); err != nil {
+		log.Fatalf("初始化内置角色失败: %v", err)
+	}
+
 	// 初始化队列
 	if cfg.Queue.Enabled {
 		queue.InitQueue()
 		queue.InitQueueServer()
-		task.Setup() // 设置和注册所有任务
+		task.Setup(cfg, db) // 设置和注册所有任务
 
 		// 注册处理器到Mux
 		for pattern, handler := range queue.HandleFunc {
@@ -76,7 +105,10 @@ func Run() {
 		}()
 	}
 
-	r := routes.SetupRoutes(db)
+	r, err := routes.SetupRoutes(db)
+	if err != nil {
+		log.Fatalf("初始化路由失败: %v", err)
+	}
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.System.Addr),
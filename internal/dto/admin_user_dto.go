@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// AdminUserListRequest 管理后台用户列表查询参数
+type AdminUserListRequest struct {
+	Page        int        `form:"page" binding:"omitempty,min=1"`
+	PageSize    int        `form:"pageSize" binding:"omitempty,min=1,max=100"`
+	Status      string     `form:"status" binding:"omitempty,oneof=active pending_verification disabled"`
+	Email       string     `form:"email" binding:"omitempty,max=255"`
+	Keyword     string     `form:"keyword" binding:"omitempty,max=100"`                       // 匹配邮箱、手机号或昵称
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02" binding:"omitempty"` // 注册日期下限（含）
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02" binding:"omitempty"`   // 注册日期上限（含当天）
+	Deleted     bool       `form:"deleted"`                                                   // 为 true 时只查询已删除的用户
+}
+
+// AdminUserResponse 管理后台用户信息响应
+type AdminUserResponse struct {
+	ID               string     `json:"id"`
+	Email            string     `json:"email"`
+	Phone            string     `json:"phone"`
+	Name             string     `json:"name"`
+	Avatar           string     `json:"avatar"`
+	Status           string     `json:"status"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AdminResetPasswordRequest 管理员重置用户密码请求，未提供密码时生成随机临时密码
+type AdminResetPasswordRequest struct {
+	Password string `json:"password" binding:"omitempty,min=6"`
+}
+
+// AdminResetPasswordResponse 管理员重置用户密码响应，临时密码只在此时返回一次
+type AdminResetPasswordResponse struct {
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+}
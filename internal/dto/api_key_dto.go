@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest 创建 API Key 请求
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=3650"` // 0 表示永不过期
+}
+
+// UpdateAPIKeyRequest 更新 API Key 请求，未提供的字段保持不变
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name" binding:"omitempty,max=100"`
+	Scopes []string `json:"scopes" binding:"omitempty,min=1,dive,required"`
+}
+
+// APIKeyResponse API Key 信息响应，不包含密钥
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse 创建 API Key 响应，Key 只在此时返回一次
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
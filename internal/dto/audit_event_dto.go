@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+// AuditEventListRequest 审计事件查询参数，导出时忽略分页参数
+type AuditEventListRequest struct {
+	Page     int        `form:"page" binding:"omitempty,min=1"`
+	PageSize int        `form:"pageSize" binding:"omitempty,min=1,max=100"`
+	Type     string     `form:"type" binding:"omitempty,max=50"`
+	Outcome  string     `form:"outcome" binding:"omitempty,oneof=success failure"`
+	ActorID  string     `form:"actor_id" binding:"omitempty,max=36"`
+	TargetID string     `form:"target_id" binding:"omitempty,max=36"`
+	IP       string     `form:"ip" binding:"omitempty,ip"`
+	From     *time.Time `form:"from" time_format:"2006-01-02" binding:"omitempty"` // 发生日期下限（含）
+	To       *time.Time `form:"to" time_format:"2006-01-02" binding:"omitempty"`   // 发生日期上限（含当天）
+}
@@ -0,0 +1,25 @@
+package dto
+
+// MFAEnrollResponse 绑定身份验证器响应
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`      // 无法扫码时手动输入的密钥
+	OTPAuthURL string `json:"otpauth_url"` // otpauth:// URI
+	QRCode     string `json:"qr_code"`     // PNG 二维码，data URI 格式
+}
+
+// MFACodeRequest 提交两步验证码请求，code 可以是 6 位 TOTP 验证码或恢复码
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse 恢复码响应，恢复码只展示一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// LoginMFARequest 两步验证登录请求
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+	ClientInfo
+}
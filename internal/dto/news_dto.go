@@ -0,0 +1,21 @@
+package dto
+
+// CreateNewsRequest 发布快讯请求
+type CreateNewsRequest struct {
+	Title       string `json:"title" binding:"required,max=200"`
+	Content     string `json:"content" binding:"required"`
+	Source      string `json:"source" binding:"required,max=200"`
+	Category    int    `json:"category"`
+	IsImportant bool   `json:"is_important"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public internal"`
+}
+
+// UpdateNewsRequest 更新快讯请求，未提供的字段保持不变
+type UpdateNewsRequest struct {
+	Title       *string `json:"title" binding:"omitempty,max=200"`
+	Content     *string `json:"content"`
+	Source      *string `json:"source" binding:"omitempty,max=200"`
+	Category    *int    `json:"category"`
+	IsImportant *bool   `json:"is_important"`
+	Visibility  *string `json:"visibility" binding:"omitempty,oneof=public internal"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=50"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"dive,min=1,max=100"`
+}
+
+// SetRolePermissionsRequest 设置角色权限请求
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions" binding:"dive,min=1,max=100"`
+}
+
+// AssignRoleRequest 为用户分配角色请求
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// RoleResponse 角色响应
+type RoleResponse struct {
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRolesResponse 用户角色与权限响应
+type UserRolesResponse struct {
+	UserID      string   `json:"user_id"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// ClientInfo 登录客户端信息，用于记录会话
+// Device 可由客户端提交（如 "iPhone 15"），IP 与 UserAgent 由处理器填充
+type ClientInfo struct {
+	Device    string `json:"device" binding:"omitempty,max=100"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+// SessionResponse 登录会话信息响应
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为发起请求的会话
+}
@@ -9,24 +9,34 @@ type UserRegisterRequest struct {
 	Name     string `json:"name" binding:"required,min=2,max=100"`
 }
 
+// VerifyEmailRequest 邮箱验证请求，令牌可通过查询参数或 JSON 提交
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
 // UserLoginRequest 用户登录请求
 type UserLoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required"`
+	ClientInfo        // 客户端 IP 同时用于登录失败限制
 }
 
 // UserLoginResponse 用户登录响应
+// 启用两步验证时只返回 MFARequired 与 MFAToken，需调用 /users/login/2fa 完成登录
 type UserLoginResponse struct {
-	User         UserInfoResponse `json:"user"`
-	AccessToken  string           `json:"access_token"`
-	RefreshToken string           `json:"refresh_token"`
-	ExpiresIn    int              `json:"expires_in"`
+	User         *UserInfoResponse `json:"user,omitempty"`
+	AccessToken  string            `json:"access_token,omitempty"`
+	RefreshToken string            `json:"refresh_token,omitempty"`
+	ExpiresIn    int               `json:"expires_in"`
+	MFARequired  bool              `json:"mfa_required,omitempty"`
+	MFAToken     string            `json:"mfa_token,omitempty"`
 }
 
 // UserInfoResponse 用户信息响应
 type UserInfoResponse struct {
 	ID        string `json:"id"`
 	Email     string `json:"email"`
+	Phone     string `json:"phone,omitempty"`
 	Name      string `json:"name"`
 	Avatar    string `json:"avatar"`
 	Status    string `json:"status"`
@@ -43,15 +53,31 @@ type UserRegisterResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// SMSLoginRequest 短信验证码登录请求
+type SMSLoginRequest struct {
+	Phone string `json:"phone" binding:"required,min=11,max=11"`
+	Code  string `json:"code" binding:"required,min=4,max=6"`
+	ClientInfo
+}
+
+// EmailLoginRequest 邮箱验证码登录请求
+type EmailLoginRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required,min=4,max=6"`
+	ClientInfo
+}
+
 // RefreshTokenResponse 刷新令牌响应
 type RefreshTokenResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 // SendSMSCodeRequest 发送短信验证码请求
 type SendSMSCodeRequest struct {
 	Phone string `json:"phone" binding:"required,min=11,max=11"`
+	IP    string `json:"-"` // 客户端 IP，由处理器填充，用于发送限制
 }
 
 // SendSMSCodeResponse 发送短信验证码响应
@@ -75,6 +101,7 @@ type VerifySMSCodeResponse struct {
 // SendEmailCodeRequest 发送邮箱验证码请求
 type SendEmailCodeRequest struct {
 	Email string `json:"email" binding:"required,email"`
+	IP    string `json:"-"` // 客户端 IP，由处理器填充，用于发送限制
 }
 
 // SendEmailCodeResponse 发送邮箱验证码响应
@@ -94,3 +121,33 @@ type VerifyEmailCodeResponse struct {
 	Valid   bool   `json:"valid"`
 	Message string `json:"message"`
 }
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	IP    string `json:"-"` // 客户端 IP，由处理器填充，用于发送限制
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Code        string `json:"code" binding:"required,min=4,max=6"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// UpdateProfileRequest 更新个人资料请求，未提供的字段保持不变
+type UpdateProfileRequest struct {
+	Name *string `json:"name" binding:"omitempty,min=2,max=100"`
+}
+
+// AvatarResponse 上传头像响应
+type AvatarResponse struct {
+	Avatar     string         `json:"avatar"`     // 默认尺寸的头像地址
+	Thumbnails map[int]string `json:"thumbnails"` // 各尺寸缩略图地址，键为边长（像素）
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminUserHandler 管理后台用户管理处理器
+type AdminUserHandler struct {
+	adminUserService services.AdminUserService
+}
+
+// NewAdminUserHandler 创建管理后台用户管理处理器实例
+func NewAdminUserHandler(db *gorm.DB) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: services.NewAdminUserService(db),
+	}
+}
+
+// ListUsers 按条件分页查询用户
+func (h *AdminUserHandler) ListUsers(c *gin.Context) {
+	var req dto.AdminUserListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 20
+	}
+
+	users, total, err := h.adminUserService.ListUsers(c, req)
+	if err != nil {
+		utils.ServerError(c, "获取用户列表失败: "+err.Error())
+		return
+	}
+
+	list := make([]dto.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		list = append(list, toAdminUserResponse(user))
+	}
+
+	utils.Success(c, gin.H{
+		"list":     list,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	})
+}
+
+// GetUser 查看用户详情
+func (h *AdminUserHandler) GetUser(c *gin.Context) {
+	user, err := h.adminUserService.GetUser(c, c.Param("id"))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, toAdminUserResponse(user))
+}
+
+// DisableUser 禁用用户
+func (h *AdminUserHandler) DisableUser(c *gin.Context) {
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUserService.DisableUser(c, operator.Id, c.Param("id")); err != nil {
+		utils.BadRequest(c, "禁用用户失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// EnableUser 启用用户
+func (h *AdminUserHandler) EnableUser(c *gin.Context) {
+	if err := h.adminUserService.EnableUser(c, c.Param("id")); err != nil {
+		utils.BadRequest(c, "启用用户失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// ResetPassword 强制重置用户密码
+func (h *AdminUserHandler) ResetPassword(c *gin.Context) {
+	var req dto.AdminResetPasswordRequest
+	// 请求体可以为空，此时生成临时密码
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequest(c, "参数验证失败")
+			return
+		}
+	}
+
+	resp, err := h.adminUserService.ResetPassword(c, c.Param("id"), req)
+	if err != nil {
+		utils.BadRequest(c, "重置密码失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// DeleteUser 删除用户（软删除）
+func (h *AdminUserHandler) DeleteUser(c *gin.Context) {
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUserService.DeleteUser(c, operator.Id, c.Param("id")); err != nil {
+		utils.BadRequest(c, "删除用户失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// RestoreUser 恢复已删除的用户
+func (h *AdminUserHandler) RestoreUser(c *gin.Context) {
+	if err := h.adminUserService.RestoreUser(c, c.Param("id")); err != nil {
+		utils.BadRequest(c, "恢复用户失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// ForceLogout 强制用户退出所有设备
+func (h *AdminUserHandler) ForceLogout(c *gin.Context) {
+	if err := h.adminUserService.ForceLogout(c, c.Param("id")); err != nil {
+		utils.BadRequest(c, "强制退出失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+func toAdminUserResponse(user *models.User) dto.AdminUserResponse {
+	resp := dto.AdminUserResponse{
+		ID:               user.ID,
+		Email:            user.EmailAddress(),
+		Phone:            user.PhoneNumber(),
+		Name:             user.Name,
+		Avatar:           user.Avatar,
+		Status:           user.Status,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+	if user.DeletedAt.Valid {
+		resp.DeletedAt = &user.DeletedAt.Time
+	}
+	return resp
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"strconv"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler API Key 处理器
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+// NewAPIKeyHandler 创建 API Key 处理器实例
+// 与认证中间件共用同一个服务实例
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey 创建 API Key
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.CreateAPIKey(c, userInfo.Id, req)
+	if err != nil {
+		utils.BadRequest(c, "创建 API Key 失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, dto.CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            rawKey,
+	})
+}
+
+// ListAPIKeys 获取当前用户的 API Key 列表
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c, userInfo.Id)
+	if err != nil {
+		utils.ServerError(c, "获取 API Key 列表失败: "+err.Error())
+		return
+	}
+
+	response := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, toAPIKeyResponse(key))
+	}
+
+	utils.Success(c, response)
+}
+
+// GetAPIKey 获取单个 API Key
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效ID")
+		return
+	}
+
+	key, err := h.apiKeyService.GetAPIKey(c, userInfo.Id, uint(id))
+	if err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, toAPIKeyResponse(key))
+}
+
+// UpdateAPIKey 更新 API Key
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效ID")
+		return
+	}
+
+	var req dto.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	key, err := h.apiKeyService.UpdateAPIKey(c, userInfo.Id, uint(id), req)
+	if err != nil {
+		utils.BadRequest(c, "更新 API Key 失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, toAPIKeyResponse(key))
+}
+
+// DeleteAPIKey 删除 API Key
+func (h *APIKeyHandler) DeleteAPIKey(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效ID")
+		return
+	}
+
+	if err := h.apiKeyService.DeleteAPIKey(c, userInfo.Id, uint(id)); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// currentUser 获取认证中间件写入的用户信息，不存在时直接返回 401
+func currentUser(c *gin.Context) (*utils.UserInfo, bool) {
+	value, exists := c.Get("user")
+	if !exists {
+		utils.Unauthorized(c, "未授权，请先登录")
+		return nil, false
+	}
+
+	userInfo, ok := value.(*utils.UserInfo)
+	if !ok {
+		utils.Unauthorized(c, "令牌格式错误")
+		return nil, false
+	}
+	return userInfo, true
+}
+
+func toAPIKeyResponse(key *models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditHandler 安全审计日志处理器
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+// NewAuditHandler 创建安全审计日志处理器实例
+func NewAuditHandler(db *gorm.DB) *AuditHandler {
+	return &AuditHandler{
+		auditService: services.NewAuditService(db),
+	}
+}
+
+// ListEvents 按条件分页查询审计事件
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	var req dto.AuditEventListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 20
+	}
+
+	events, total, err := h.auditService.ListEvents(c, req)
+	if err != nil {
+		utils.ServerError(c, "获取审计日志失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"list":     events,
+		"total":    total,
+		"page":     req.Page,
+		"pageSize": req.PageSize,
+	})
+}
+
+// ExportEvents 以 CSV 文件导出符合条件的审计事件
+func (h *AuditHandler) ExportEvents(c *gin.Context) {
+	var req dto.AuditEventListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-events-%s.csv", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// 数据边查询边写出，响应头发送后出错只能中断输出
+	if err := h.auditService.ExportEvents(c, req, c.Writer); err != nil {
+		logger.Error("导出审计日志失败", zap.Error(err))
+		_ = c.Error(err)
+	}
+}
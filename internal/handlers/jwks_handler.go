@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// JWKSHandler 公钥集合处理器
+type JWKSHandler struct{}
+
+// NewJWKSHandler 创建公钥集合处理器实例
+func NewJWKSHandler() *JWKSHandler {
+	return &JWKSHandler{}
+}
+
+// GetJWKS 发布用于验证本服务令牌的公钥集合
+// 按 RFC 7517 直接返回 JWKS 文档，不使用统一响应包装，便于其他服务直接使用
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	jwtUtil, err := utils.GetJWTUtil()
+	if err != nil {
+		logger.Error("JWT工具初始化失败", zap.Error(err))
+		utils.ServerError(c, "获取公钥失败")
+		return
+	}
+
+	jwks, err := jwtUtil.JWKS()
+	if err != nil {
+		logger.Error("导出公钥集合失败", zap.Error(err))
+		utils.ServerError(c, "获取公钥失败")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
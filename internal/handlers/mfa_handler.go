@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MFAHandler 两步验证处理器
+type MFAHandler struct {
+	mfaService services.MFAService
+}
+
+// NewMFAHandler 创建两步验证处理器实例
+func NewMFAHandler(db *gorm.DB) *MFAHandler {
+	return &MFAHandler{
+		mfaService: services.NewMFAService(db),
+	}
+}
+
+// Enroll 绑定身份验证器，返回 otpauth URI 与二维码
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.mfaService.Enroll(c, userInfo.Id)
+	if err != nil {
+		utils.BadRequest(c, "绑定身份验证器失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// Confirm 确认绑定并启用两步验证，返回恢复码
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	resp, err := h.mfaService.Confirm(c, userInfo.Id, req.Code)
+	if err != nil {
+		respondMFAError(c, "启用两步验证失败: ", err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// Disable 停用两步验证
+func (h *MFAHandler) Disable(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	if err := h.mfaService.Disable(c, userInfo.Id, req.Code); err != nil {
+		respondMFAError(c, "停用两步验证失败: ", err)
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	resp, err := h.mfaService.RegenerateRecoveryCodes(c, userInfo.Id, req.Code)
+	if err != nil {
+		respondMFAError(c, "生成恢复码失败: ", err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// respondMFAError 两步验证操作失败时的响应，失败次数过多被锁定时返回 429
+func respondMFAError(c *gin.Context, prefix string, err error) {
+	if errors.Is(err, services.ErrMFALocked) {
+		utils.Error(c, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	utils.BadRequest(c, prefix+err.Error())
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
+	"mygoframe/internal/dto"
 	"mygoframe/internal/services"
+	"mygoframe/pkg/authz"
 	"mygoframe/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -60,3 +63,49 @@ func (h *NewsHandler) GetNewsList(c *gin.Context) {
 		"pageSize": pageSize,
 	})
 }
+
+func (h *NewsHandler) CreateNews(c *gin.Context) {
+	var req dto.CreateNewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	news, err := h.service.CreateNews(c, req)
+	if err != nil {
+		if errors.Is(err, authz.ErrForbidden) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
+		utils.ServerError(c, "发布快讯失败")
+		return
+	}
+
+	utils.Success(c, news)
+}
+
+func (h *NewsHandler) UpdateNews(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效ID")
+		return
+	}
+
+	var req dto.UpdateNewsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	news, err := h.service.UpdateNews(c, uint(id), req)
+	if err != nil {
+		if errors.Is(err, authz.ErrForbidden) {
+			utils.Forbidden(c, err.Error())
+			return
+		}
+		utils.BadRequest(c, "更新快讯失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, news)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"strconv"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RoleHandler 角色权限处理器
+type RoleHandler struct {
+	roleService services.RoleService
+}
+
+// NewRoleHandler 创建角色权限处理器实例
+func NewRoleHandler(db *gorm.DB) *RoleHandler {
+	return &RoleHandler{
+		roleService: services.NewRoleService(db),
+	}
+}
+
+// ListRoles 获取角色列表
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c)
+	if err != nil {
+		utils.ServerError(c, "获取角色列表失败: "+err.Error())
+		return
+	}
+
+	response := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, toRoleResponse(role))
+	}
+
+	utils.Success(c, response)
+}
+
+// CreateRole 创建角色
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c, req)
+	if err != nil {
+		utils.BadRequest(c, "创建角色失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, toRoleResponse(role))
+}
+
+// SetRolePermissions 设置角色权限
+func (h *RoleHandler) SetRolePermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "无效ID")
+		return
+	}
+
+	var req dto.SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	role, err := h.roleService.SetRolePermissions(c, uint(id), req.Permissions)
+	if err != nil {
+		utils.BadRequest(c, "设置角色权限失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, toRoleResponse(role))
+}
+
+// GetUserRoles 获取用户的角色与权限
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	userID := c.Param("id")
+
+	roles, err := h.roleService.GetUserRoles(c, userID)
+	if err != nil {
+		utils.ServerError(c, "获取用户角色失败: "+err.Error())
+		return
+	}
+
+	permissions, err := h.roleService.GetUserPermissions(c, userID)
+	if err != nil {
+		utils.ServerError(c, "获取用户权限失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, dto.UserRolesResponse{
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: permissions,
+	})
+}
+
+// AssignRole 为用户分配角色
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	if err := h.roleService.AssignRole(c, c.Param("id"), req.Role); err != nil {
+		utils.BadRequest(c, "分配角色失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// RevokeRole 移除用户的角色
+func (h *RoleHandler) RevokeRole(c *gin.Context) {
+	if err := h.roleService.RevokeRole(c, c.Param("id"), c.Param("role")); err != nil {
+		utils.BadRequest(c, "移除角色失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+func toRoleResponse(role *models.Role) dto.RoleResponse {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+	}
+}
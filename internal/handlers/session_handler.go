@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"mygoframe/internal/dto"
+	"mygoframe/internal/services"
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	sessionService services.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler(db *gorm.DB) *SessionHandler {
+	return &SessionHandler{
+		sessionService: services.NewSessionService(db),
+	}
+}
+
+// ListSessions 获取当前用户已登录的设备
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c, userInfo.Id)
+	if err != nil {
+		utils.ServerError(c, "获取会话列表失败: "+err.Error())
+		return
+	}
+
+	// 访问令牌所属的令牌族即当前会话
+	var currentFamily string
+	if value, exists := c.Get("claims"); exists {
+		if claims, ok := value.(*utils.Claims); ok {
+			currentFamily = claims.Family
+		}
+	}
+
+	response := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, dto.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentFamily,
+		})
+	}
+
+	utils.Success(c, response)
+}
+
+// RevokeSession 退出指定设备
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c, userInfo.Id, c.Param("id")); err != nil {
+		utils.NotFound(c, err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
 	"strings"
 
 	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
 	"mygoframe/internal/services"
 	"mygoframe/internal/task"
 	"mygoframe/pkg/utils"
@@ -27,10 +30,10 @@ func NewUserHandler(db *gorm.DB) *UserHandler {
 
 // TestQueue 测试队列
 func (h *UserHandler) TestQueue(c *gin.Context) {
-	userID := 123
+	userID := "123"
 	queueName := "critical"
 
-	info, err := task.EnqueueWelcomeEmailTask(userID, queueName)
+	info, err := task.EnqueueWelcomeEmailTask(task.WelcomeEmailPayload{UserID: userID}, queueName)
 	if err != nil {
 		utils.ServerError(c, "任务入队失败")
 		return
@@ -80,7 +83,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 	response := dto.UserRegisterResponse{
 		ID:        user.ID,
-		Email:     user.Email,
+		Email:     user.EmailAddress(),
 		Name:      user.Name,
 		Avatar:    user.Avatar,
 		Status:    user.Status,
@@ -90,6 +93,22 @@ func (h *UserHandler) Register(c *gin.Context) {
 	utils.Success(c, response)
 }
 
+// VerifyEmail 验证注册邮箱，支持直接打开邮件中的链接（GET）或提交令牌（POST）
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	if err := h.userService.VerifyEmail(c, req.Token); err != nil {
+		utils.BadRequest(c, "邮箱验证失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, gin.H{"message": "邮箱验证成功，请登录"})
+}
+
 // Login 用户登录
 func (h *UserHandler) Login(c *gin.Context) {
 	var req dto.UserLoginRequest
@@ -98,8 +117,75 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	fillClientInfo(c, &req.ClientInfo)
+
 	resp, err := h.userService.Login(c, req) // 传递context
 	if err != nil {
+		if errors.Is(err, services.ErrLoginLocked) {
+			utils.Error(c, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		respondLoginError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// LoginWithSMS 短信验证码登录
+func (h *UserHandler) LoginWithSMS(c *gin.Context) {
+	var req dto.SMSLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	fillClientInfo(c, &req.ClientInfo)
+
+	resp, err := h.userService.LoginWithSMS(c, req)
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// LoginWithEmail 邮箱验证码登录
+func (h *UserHandler) LoginWithEmail(c *gin.Context) {
+	var req dto.EmailLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	fillClientInfo(c, &req.ClientInfo)
+
+	resp, err := h.userService.LoginWithEmail(c, req)
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// LoginWith2FA 两步验证登录
+func (h *UserHandler) LoginWith2FA(c *gin.Context) {
+	var req dto.LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	fillClientInfo(c, &req.ClientInfo)
+
+	resp, err := h.userService.LoginWith2FA(c, req)
+	if err != nil {
+		if errors.Is(err, services.ErrMFALocked) {
+			utils.Error(c, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		utils.Unauthorized(c, "登录失败: "+err.Error())
 		return
 	}
@@ -115,9 +201,11 @@ func (h *UserHandler) SendEmailCode(c *gin.Context) {
 		return
 	}
 
+	req.IP = c.ClientIP()
+
 	resp, err := h.userService.SendEmailCode(c, req)
 	if err != nil {
-		utils.ServerError(c, "发送验证码失败: "+err.Error())
+		respondSendCodeError(c, err)
 		return
 	}
 
@@ -151,7 +239,10 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 	refreshToken = strings.TrimPrefix(refreshToken, "Bearer ")
 
-	resp, err := h.userService.RefreshToken(c, refreshToken) // 传递context
+	var client dto.ClientInfo
+	fillClientInfo(c, &client)
+
+	resp, err := h.userService.RefreshToken(c, refreshToken, client) // 传递context
 	if err != nil {
 		utils.Unauthorized(c, "刷新令牌失败: "+err.Error())
 		return
@@ -178,6 +269,88 @@ func (h *UserHandler) Logout(c *gin.Context) {
 	utils.Success(c, nil)
 }
 
+// LogoutAll 退出所有设备
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	claims, exists := c.Get("user")
+	if !exists {
+		utils.Unauthorized(c, "未授权，请先登录")
+		return
+	}
+
+	userClaims, ok := claims.(*utils.UserInfo)
+	if !ok {
+		utils.Unauthorized(c, "令牌格式错误")
+		return
+	}
+
+	if err := h.userService.LogoutAll(c, userClaims.Id); err != nil {
+		utils.ServerError(c, "退出所有设备失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// ForgotPassword 忘记密码，发送重置验证码
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	req.IP = c.ClientIP()
+
+	resp, err := h.userService.ForgotPassword(c, req)
+	if err != nil {
+		respondSendCodeError(c, err)
+		return
+	}
+
+	utils.Success(c, resp)
+}
+
+// ResetPassword 使用验证码重置密码
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	if err := h.userService.ResetPassword(c, req); err != nil {
+		utils.BadRequest(c, "重置密码失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
+// ChangePassword 修改密码，成功后需要重新登录
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	if err := h.userService.ChangePassword(c, userInfo.Id, req); err != nil {
+		if errors.Is(err, services.ErrLoginLocked) {
+			utils.Error(c, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		utils.BadRequest(c, "修改密码失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, nil)
+}
+
 // GetProfile 获取用户信息（需要登录）
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	claims, exists := c.Get("user")
@@ -203,16 +376,64 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	response := dto.UserInfoResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		Avatar:    user.Avatar,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+	utils.Success(c, toUserInfoResponse(user))
+}
+
+// UpdateProfile 更新个人资料
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
 	}
 
-	utils.Success(c, response)
+	var req dto.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "参数验证失败")
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c, userInfo.Id, req)
+	if err != nil {
+		utils.BadRequest(c, "更新个人资料失败: "+err.Error())
+		return
+	}
+
+	utils.Success(c, toUserInfoResponse(user))
+}
+
+// UploadAvatar 上传头像，表单字段名为 avatar
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userInfo, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		utils.BadRequest(c, "请上传头像文件")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	resp, err := h.userService.UpdateAvatar(c, userInfo.Id, file)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAvatarTooLarge):
+			utils.Error(c, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, utils.ErrUnsupportedImage), errors.Is(err, utils.ErrImageTooLarge):
+			utils.BadRequest(c, err.Error())
+		default:
+			utils.ServerError(c, "上传头像失败: "+err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, resp)
 }
 
 // SendSMSCode 发送短信验证码
@@ -223,9 +444,11 @@ func (h *UserHandler) SendSMSCode(c *gin.Context) {
 		return
 	}
 
+	req.IP = c.ClientIP()
+
 	resp, err := h.userService.SendSMSCode(c, req)
 	if err != nil {
-		utils.ServerError(c, "发送验证码失败: "+err.Error())
+		respondSendCodeError(c, err)
 		return
 	}
 
@@ -248,3 +471,40 @@ func (h *UserHandler) VerifySMSCode(c *gin.Context) {
 
 	utils.Success(c, resp)
 }
+
+// respondSendCodeError 发送验证码失败时的响应，触发频率或配额限制时返回 429
+func respondSendCodeError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrCodeCooldown) || errors.Is(err, services.ErrCodeQuotaExceeded) {
+		utils.Error(c, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	utils.ServerError(c, "发送验证码失败: "+err.Error())
+}
+
+// respondLoginError 登录失败时的响应，邮箱未验证时返回专用错误码
+func respondLoginError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		utils.ErrorWithCode(c, http.StatusForbidden, utils.CodeEmailNotVerified, err.Error())
+		return
+	}
+	utils.Unauthorized(c, "登录失败: "+err.Error())
+}
+
+// fillClientInfo 填充客户端 IP 与 User-Agent，用于登录限制和会话记录
+func fillClientInfo(c *gin.Context, client *dto.ClientInfo) {
+	client.IP = c.ClientIP()
+	client.UserAgent = c.Request.UserAgent()
+}
+
+// toUserInfoResponse 构造用户信息响应
+func toUserInfoResponse(user *models.User) dto.UserInfoResponse {
+	return dto.UserInfoResponse{
+		ID:        user.ID,
+		Email:     user.EmailAddress(),
+		Phone:     user.PhoneNumber(),
+		Name:      user.Name,
+		Avatar:    user.Avatar,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
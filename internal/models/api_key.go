@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API Key 可授予的权限范围
+const (
+	ScopeProfileRead = "profile:read" // 读取所属用户的资料
+	ScopeNewsRead    = "news:read"    // 读取快讯
+	ScopeNewsWrite   = "news:write"   // 发布和编辑快讯
+)
+
+// APIKeyScopes 所有可用的权限范围
+var APIKeyScopes = []string{ScopeProfileRead, ScopeNewsRead, ScopeNewsWrite}
+
+// APIKey 供批处理任务和合作方集成使用的 API Key
+// 只保存密钥的 SHA-256 摘要，Prefix 用于查找，明文只在创建时返回一次
+type APIKey struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     string         `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string         `gorm:"type:varchar(16);not null;uniqueIndex:idx_api_keys_prefix" json:"prefix"`
+	SecretHash string         `gorm:"type:varchar(64);not null" json:"-"`
+	Scopes     []string       `gorm:"type:text;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsExpired 判断 API Key 是否已过期，未设置过期时间表示永不过期
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// HasScope 判断 API Key 是否拥有指定权限范围
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// 审计事件类型
+const (
+	AuditEventLogin          = "login"           // 密码、验证码或两步验证登录
+	AuditEventTokenRefresh   = "token_refresh"   // 刷新令牌
+	AuditEventLogout         = "logout"          // 登出当前设备
+	AuditEventLogoutAll      = "logout_all"      // 退出所有设备（含管理员强制退出）
+	AuditEventSessionRevoke  = "session_revoke"  // 退出指定设备
+	AuditEventCodeSent       = "code_sent"       // 发送短信或邮箱验证码
+	AuditEventCodeVerified   = "code_verified"   // 校验短信或邮箱验证码
+	AuditEventPasswordChange = "password_change" // 修改、找回或管理员重置密码
+	AuditEventRoleChange     = "role_change"     // 分配或移除角色
+	AuditEventUserStatus     = "user_status"     // 管理员禁用、启用、删除或恢复用户
+	AuditEventTokenRejected  = "token_rejected"  // 认证中间件拒绝了无效或已吊销的令牌
+)
+
+// 审计事件结果
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent 安全审计事件，记录认证相关操作的执行者、来源和结果
+type AuditEvent struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	Type      string    `gorm:"type:varchar(50);not null;index" json:"type"`
+	Outcome   string    `gorm:"type:varchar(20);not null;index" json:"outcome"`
+	ActorID   string    `gorm:"type:varchar(36);index" json:"actor_id"`  // 执行操作的用户，未登录时为空
+	TargetID  string    `gorm:"type:varchar(36);index" json:"target_id"` // 被操作的用户，本人操作时与 ActorID 相同
+	Subject   string    `gorm:"type:varchar(255)" json:"subject"`        // 提交的账号标识（邮箱、手机号），账号不存在时用于追溯
+	IP        string    `gorm:"type:varchar(45);index" json:"ip"`
+	UserAgent string    `gorm:"type:varchar(512)" json:"user_agent"`
+	RequestID string    `gorm:"type:varchar(64)" json:"request_id"`
+	Detail    string    `gorm:"type:varchar(500)" json:"detail"` // 失败原因或操作说明
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
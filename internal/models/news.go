@@ -6,6 +6,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// 快讯可见性
+const (
+	NewsVisibilityPublic   = "public"   // 所有人可见
+	NewsVisibilityInternal = "internal" // 仅授权策略允许的用户可见
+)
+
 // News 快讯模型
 type News struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
@@ -14,6 +20,8 @@ type News struct {
 	Source      string         `gorm:"size:200;not null" json:"source"`
 	Category    int            `gorm:"type:int;default:1" json:"category"` // 1:快讯
 	IsImportant bool           `gorm:"default:false" json:"is_important"`
+	AuthorID    string         `gorm:"type:varchar(36);index" json:"author_id"`
+	Visibility  string         `gorm:"size:20;not null;default:public;index" json:"visibility"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Session 用户登录会话，对应一个刷新令牌族，用于查看和管理已登录的设备
+// ID 与令牌族ID相同，同一次登录及其后续刷新共用一条记录
+type Session struct {
+	ID         string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID     string     `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	Device     string     `gorm:"type:varchar(100)" json:"device"`
+	UserAgent  string     `gorm:"type:varchar(512)" json:"user_agent"`
+	IP         string     `gorm:"type:varchar(45)" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive 判断会话是否仍然有效（未吊销且刷新令牌未过期）
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
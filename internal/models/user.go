@@ -6,17 +6,30 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户状态
+const (
+	UserStatusActive = "active"
+	// UserStatusPendingVerification 已注册但尚未验证邮箱，不能登录
+	UserStatusPendingVerification = "pending_verification"
+	// UserStatusDisabled 被管理员禁用，不能登录
+	UserStatusDisabled = "disabled"
+)
+
 // User 用户模型
 type User struct {
-	ID        string         `gorm:"type:varchar(36);primaryKey" json:"id"`
-	Email     string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_users_email" json:"email"`
-	Password  string         `gorm:"type:varchar(255);not null" json:"-"`
-	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
-	Avatar    string         `gorm:"type:varchar(255)" json:"avatar"`
-	Status    string         `gorm:"type:varchar(20);default:active" json:"status"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               string         `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Email            *string        `gorm:"type:varchar(255);uniqueIndex:idx_users_email" json:"email"`          // 通过手机号注册的用户可为空
+	Phone            *string        `gorm:"type:varchar(20);uniqueIndex:idx_users_phone" json:"phone,omitempty"` // 通过邮箱注册的用户可为空
+	Password         string         `gorm:"type:varchar(255);not null" json:"-"`
+	Name             string         `gorm:"type:varchar(100);not null" json:"name"`
+	Avatar           string         `gorm:"type:varchar(255)" json:"avatar"`
+	Status           string         `gorm:"type:varchar(20);default:active" json:"status"`
+	Roles            []Role         `gorm:"many2many:user_roles;" json:"roles,omitempty"`
+	TOTPSecret       string         `gorm:"type:varchar(255)" json:"-"` // 加密后的 TOTP 密钥，绑定未确认时 TwoFactorEnabled 为 false
+	TwoFactorEnabled bool           `gorm:"default:false" json:"two_factor_enabled"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
@@ -25,5 +38,26 @@ func (User) TableName() string {
 }
 
 func (u *User) IsActive() bool {
-	return u.Status == "active"
+	return u.Status == UserStatusActive
+}
+
+// IsPendingVerification 是否等待邮箱验证
+func (u *User) IsPendingVerification() bool {
+	return u.Status == UserStatusPendingVerification
+}
+
+// EmailAddress 返回用户邮箱，未绑定时为空字符串
+func (u *User) EmailAddress() string {
+	if u.Email == nil {
+		return ""
+	}
+	return *u.Email
+}
+
+// PhoneNumber 返回用户手机号，未绑定时为空字符串
+func (u *User) PhoneNumber() string {
+	if u.Phone == nil {
+		return ""
+	}
+	return *u.Phone
 }
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mygoframe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository API Key 仓储接口
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	FindByIDAndUser(ctx context.Context, id uint, userID string) (*models.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.APIKey, error)
+	Update(ctx context.Context, key *models.APIKey) error
+	Delete(ctx context.Context, id uint, userID string) (bool, error)
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+// apiKeyRepository API Key 仓储实现
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository 创建 API Key 仓储实例
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+// Create 创建 API Key
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("创建 API Key 失败: %w", err)
+	}
+	return nil
+}
+
+// FindByPrefix 根据前缀查找 API Key
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询 API Key 失败: %w", err)
+	}
+	return &key, nil
+}
+
+// FindByIDAndUser 查找属于指定用户的 API Key
+func (r *apiKeyRepository) FindByIDAndUser(ctx context.Context, id uint, userID string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询 API Key 失败: %w", err)
+	}
+	return &key, nil
+}
+
+// ListByUser 获取用户的所有 API Key
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("查询 API Key 列表失败: %w", err)
+	}
+	return keys, nil
+}
+
+// Update 更新 API Key 的名称和权限范围
+// 只写入这些字段，避免用读取时的旧值覆盖并发写入的最后使用时间
+func (r *apiKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	if err := r.db.WithContext(ctx).Model(key).Select("name", "scopes", "updated_at").Updates(key).Error; err != nil {
+		return fmt.Errorf("更新 API Key 失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除属于指定用户的 API Key（软删除），返回是否删除了记录
+func (r *apiKeyRepository) Delete(ctx context.Context, id uint, userID string) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return false, fmt.Errorf("删除 API Key 失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchLastUsed 更新最后使用时间，不修改 updated_at
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("更新 API Key 使用时间失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mygoframe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditEventRepository 审计事件仓储接口
+type AuditEventRepository interface {
+	Create(ctx context.Context, event *models.AuditEvent) error
+	List(ctx context.Context, query AuditEventQuery, offset, limit int) ([]*models.AuditEvent, int64, error)
+	Each(ctx context.Context, query AuditEventQuery, batchSize int, fn func([]*models.AuditEvent) error) error
+}
+
+// AuditEventQuery 审计事件查询条件，零值字段不参与过滤
+type AuditEventQuery struct {
+	Type     string
+	Outcome  string
+	ActorID  string
+	TargetID string
+	IP       string
+	From     *time.Time // 发生时间下限（含）
+	To       *time.Time // 发生时间上限（不含）
+}
+
+// auditEventRepository 审计事件仓储实现
+type auditEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditEventRepository 创建审计事件仓储实例
+func NewAuditEventRepository(db *gorm.DB) AuditEventRepository {
+	return &auditEventRepository{
+		db: db,
+	}
+}
+
+// Create 写入审计事件
+func (r *auditEventRepository) Create(ctx context.Context, event *models.AuditEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("写入审计事件失败: %w", err)
+	}
+	return nil
+}
+
+// List 按条件分页查询审计事件，最近发生的排在前面
+func (r *auditEventRepository) List(ctx context.Context, query AuditEventQuery, offset, limit int) ([]*models.AuditEvent, int64, error) {
+	db := r.filter(r.db.WithContext(ctx).Model(&models.AuditEvent{}), query)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计审计事件数量失败: %w", err)
+	}
+
+	var events []*models.AuditEvent
+	if err := db.Order("id DESC").Offset(offset).Limit(limit).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询审计事件失败: %w", err)
+	}
+	return events, total, nil
+}
+
+// Each 按 ID 顺序分批读取符合条件的审计事件，用于导出大量数据
+func (r *auditEventRepository) Each(ctx context.Context, query AuditEventQuery, batchSize int, fn func([]*models.AuditEvent) error) error {
+	var events []*models.AuditEvent
+	result := r.filter(r.db.WithContext(ctx), query).FindInBatches(&events, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(events)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("读取审计事件失败: %w", result.Error)
+	}
+	return nil
+}
+
+// filter 应用查询条件
+func (r *auditEventRepository) filter(db *gorm.DB, query AuditEventQuery) *gorm.DB {
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if query.Outcome != "" {
+		db = db.Where("outcome = ?", query.Outcome)
+	}
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.TargetID != "" {
+		db = db.Where("target_id = ?", query.TargetID)
+	}
+	if query.IP != "" {
+		db = db.Where("ip = ?", query.IP)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	return db
+}
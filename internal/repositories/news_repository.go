@@ -14,6 +14,8 @@ type NewsRepository interface {
 	Update(ctx context.Context, news *models.News) error
 	Delete(ctx context.Context, id uint) error
 	Count(ctx context.Context) (int64, error)
+	ListByVisibility(ctx context.Context, visibilities []string, offset, limit int) ([]*models.News, error)
+	CountByVisibility(ctx context.Context, visibilities []string) (int64, error)
 }
 
 type newsRepository struct {
@@ -56,3 +58,21 @@ func (r *newsRepository) Count(ctx context.Context) (int64, error) {
 	err := r.db.WithContext(ctx).Model(&models.News{}).Count(&count).Error
 	return count, err
 }
+
+func (r *newsRepository) ListByVisibility(ctx context.Context, visibilities []string, offset, limit int) ([]*models.News, error) {
+	var newsList []*models.News
+	if len(visibilities) == 0 {
+		return newsList, nil
+	}
+	err := r.db.WithContext(ctx).Where("visibility IN ?", visibilities).Offset(offset).Limit(limit).Find(&newsList).Error
+	return newsList, err
+}
+
+func (r *newsRepository) CountByVisibility(ctx context.Context, visibilities []string) (int64, error) {
+	var count int64
+	if len(visibilities) == 0 {
+		return 0, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.News{}).Where("visibility IN ?", visibilities).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mygoframe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository 恢复码仓储接口
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID string, codeHashes []string) error
+	Consume(ctx context.Context, userID string, codeHash string) (bool, error)
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// recoveryCodeRepository 恢复码仓储实现
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeRepository 创建恢复码仓储实例
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Replace 删除用户原有的恢复码并保存新的一组
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID string, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("删除恢复码失败: %w", err)
+		}
+
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return fmt.Errorf("保存恢复码失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Consume 将未使用的恢复码标记为已使用，返回是否成功
+// 通过带条件的 UPDATE 保证并发请求下同一恢复码只能使用一次
+func (r *recoveryCodeRepository) Consume(ctx context.Context, userID string, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("使用恢复码失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteByUser 删除用户的全部恢复码
+func (r *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("删除恢复码失败: %w", err)
+	}
+	return nil
+}
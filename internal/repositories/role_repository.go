@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"mygoframe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository 角色仓储接口
+type RoleRepository interface {
+	Create(ctx context.Context, role *models.Role) error
+	FindByID(ctx context.Context, id uint) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	FirstOrCreate(ctx context.Context, name string, description string) (*models.Role, error)
+	List(ctx context.Context) ([]*models.Role, error)
+	ReplacePermissions(ctx context.Context, role *models.Role, permissions []string) error
+	AssignToUser(ctx context.Context, userID string, role *models.Role) error
+	RemoveFromUser(ctx context.Context, userID string, role *models.Role) error
+	GetUserRoleNames(ctx context.Context, userID string) ([]string, error)
+	GetUserPermissionNames(ctx context.Context, userID string) ([]string, error)
+	GetUserIDsByRole(ctx context.Context, roleID uint) ([]string, error)
+}
+
+// roleRepository 角色仓储实现
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository 创建角色仓储实例
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+// Create 创建角色
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	if err := r.db.WithContext(ctx).Create(role).Error; err != nil {
+		return fmt.Errorf("创建角色失败: %w", err)
+	}
+	return nil
+}
+
+// FindByID 根据ID查找角色（包含权限）
+func (r *roleRepository) FindByID(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", id).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	return &role, nil
+}
+
+// FindByName 根据名称查找角色
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询角色失败: %w", err)
+	}
+	return &role, nil
+}
+
+// FirstOrCreate 按名称查找角色，不存在时创建
+func (r *roleRepository) FirstOrCreate(ctx context.Context, name string, description string) (*models.Role, error) {
+	role := models.Role{Name: name}
+	err := r.db.WithContext(ctx).
+		Where("name = ?", name).
+		Attrs(models.Role{Description: description}).
+		FirstOrCreate(&role).Error
+	if err != nil {
+		return nil, fmt.Errorf("初始化角色失败: %w", err)
+	}
+	return &role, nil
+}
+
+// List 获取所有角色（包含权限）
+func (r *roleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	if err := r.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("查询角色列表失败: %w", err)
+	}
+	return roles, nil
+}
+
+// ReplacePermissions 替换角色的权限集合，不存在的权限会自动创建
+func (r *roleRepository) ReplacePermissions(ctx context.Context, role *models.Role, permissions []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		perms := make([]models.Permission, 0, len(permissions))
+		for _, name := range permissions {
+			perm := models.Permission{Name: name}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&perm).Error; err != nil {
+				return fmt.Errorf("创建权限失败: %w", err)
+			}
+			if err := tx.Where("name = ?", name).First(&perm).Error; err != nil {
+				return fmt.Errorf("查询权限失败: %w", err)
+			}
+			perms = append(perms, perm)
+		}
+
+		if err := tx.Model(role).Association("Permissions").Replace(perms); err != nil {
+			return fmt.Errorf("更新角色权限失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// AssignToUser 为用户分配角色
+func (r *roleRepository) AssignToUser(ctx context.Context, userID string, role *models.Role) error {
+	if err := r.db.WithContext(ctx).Model(&models.User{ID: userID}).Association("Roles").Append(role); err != nil {
+		return fmt.Errorf("分配角色失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveFromUser 移除用户的角色
+func (r *roleRepository) RemoveFromUser(ctx context.Context, userID string, role *models.Role) error {
+	if err := r.db.WithContext(ctx).Model(&models.User{ID: userID}).Association("Roles").Delete(role); err != nil {
+		return fmt.Errorf("移除角色失败: %w", err)
+	}
+	return nil
+}
+
+// GetUserRoleNames 获取用户的角色名称列表
+func (r *roleRepository) GetUserRoleNames(ctx context.Context, userID string) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).
+		Table("roles").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Pluck("roles.name", &names).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询用户角色失败: %w", err)
+	}
+	return names, nil
+}
+
+// GetUserPermissionNames 获取用户通过角色获得的全部权限名称
+func (r *roleRepository) GetUserPermissionNames(ctx context.Context, userID string) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).
+		Table("permissions").
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Order("permissions.name").
+		Pluck("permissions.name", &names).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询用户权限失败: %w", err)
+	}
+	return names, nil
+}
+
+// GetUserIDsByRole 获取拥有指定角色的所有用户ID
+func (r *roleRepository) GetUserIDsByRole(ctx context.Context, roleID uint) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).
+		Table("user_roles").
+		Where("role_id = ?", roleID).
+		Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询角色用户失败: %w", err)
+	}
+	return ids, nil
+}
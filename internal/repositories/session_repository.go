@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mygoframe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository 登录会话仓储接口
+type SessionRepository interface {
+	Save(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id string) (*models.Session, error)
+	Touch(ctx context.Context, session *models.Session) (bool, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]*models.Session, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeAllByUser(ctx context.Context, userID string, revokedAt time.Time) error
+}
+
+// sessionRepository 登录会话仓储实现
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository 创建登录会话仓储实例
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+// Save 创建或更新会话
+func (r *sessionRepository) Save(ctx context.Context, session *models.Session) error {
+	if err := r.db.WithContext(ctx).Save(session).Error; err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+	return nil
+}
+
+// Touch 只更新未吊销会话的活跃时间、有效期与客户端信息，返回是否已更新
+// 不写回其他字段，避免覆盖并发的吊销
+func (r *sessionRepository) Touch(ctx context.Context, session *models.Session) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Updates(map[string]interface{}{
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+			"ip":           session.IP,
+			"user_agent":   session.UserAgent,
+			"device":       session.Device,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("更新会话失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByID 根据ID查找会话
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	return &session, nil
+}
+
+// ListActiveByUser 获取用户未吊销且未过期的会话，最近活跃的排在前面
+func (r *sessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询会话列表失败: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke 吊销单个会话
+func (r *sessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("吊销会话失败: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllByUser 吊销用户的全部会话
+func (r *sessionRepository) RevokeAllByUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", revokedAt).Error
+	if err != nil {
+		return fmt.Errorf("吊销会话失败: %w", err)
+	}
+	return nil
+}
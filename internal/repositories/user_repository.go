@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"mygoframe/internal/models"
 
@@ -14,9 +16,27 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByEmail(email string) (*models.User, error)
+	FindByPhone(ctx context.Context, phone string) (*models.User, error)
 	FindByID(ctx context.Context, id string) (*models.User, error)
+	FindByIDWithDeleted(ctx context.Context, id string) (*models.User, error)
+	List(ctx context.Context, query UserQuery, offset, limit int) ([]*models.User, int64, error)
 	Update(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, id string, oldHash string, newHash string) (bool, error)
+	UpdateStatus(ctx context.Context, id string, status string) error
+	UpdateColumns(ctx context.Context, id string, columns map[string]interface{}) error
 	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	PurgeUnverified(ctx context.Context, before time.Time) (int64, error)
+}
+
+// UserQuery 用户列表查询条件，零值字段不参与过滤
+type UserQuery struct {
+	Status      string
+	Email       string     // 邮箱模糊匹配
+	Keyword     string     // 匹配邮箱、手机号或昵称
+	CreatedFrom *time.Time // 注册时间下限（含）
+	CreatedTo   *time.Time // 注册时间上限（不含）
+	Deleted     bool       // 为 true 时只查询已删除的用户
 }
 
 // userRepository 用户仓储实现
@@ -52,6 +72,19 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByPhone 根据手机号查找用户
+func (r *userRepository) FindByPhone(ctx context.Context, phone string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("phone = ?", phone).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	return &user, nil
+}
+
 // FindByID 根据ID查找用户
 func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
@@ -65,6 +98,54 @@ func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User,
 	return &user, nil
 }
 
+// FindByIDWithDeleted 根据ID查找用户，包含已软删除的用户
+func (r *userRepository) FindByIDWithDeleted(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	return &user, nil
+}
+
+// List 按条件分页查询用户，返回当前页和总数，最近注册的排在前面
+func (r *userRepository) List(ctx context.Context, query UserQuery, offset, limit int) ([]*models.User, int64, error) {
+	db := r.db.WithContext(ctx).Model(&models.User{})
+	if query.Deleted {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Email != "" {
+		db = db.Where("email LIKE ? ESCAPE '!'", "%"+escapeLike(query.Email)+"%")
+	}
+	if query.Keyword != "" {
+		keyword := "%" + escapeLike(query.Keyword) + "%"
+		db = db.Where("email LIKE ? ESCAPE '!' OR phone LIKE ? ESCAPE '!' OR name LIKE ? ESCAPE '!'", keyword, keyword, keyword)
+	}
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		db = db.Where("created_at < ?", *query.CreatedTo)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计用户数量失败: %w", err)
+	}
+
+	var users []*models.User
+	if err := db.Order("created_at DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询用户列表失败: %w", err)
+	}
+	return users, total, nil
+}
+
 // Update 更新用户信息
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
@@ -73,6 +154,41 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// UpdatePassword 只更新密码哈希，且仅当当前哈希仍为 oldHash 时更新，返回是否已更新
+// 密码已被并发修改时不覆盖，也不会写回其他字段的旧值
+func (r *userRepository) UpdatePassword(ctx context.Context, id string, oldHash string, newHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND password = ?", id, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		return false, fmt.Errorf("更新密码失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateStatus 只更新用户状态
+func (r *userRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+	if err != nil {
+		return fmt.Errorf("更新用户状态失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateColumns 只更新指定的列，键为数据库列名
+// 单个字段的修改应使用该方法而不是 Update，避免整行保存时覆盖并发修改的其他字段
+func (r *userRepository) UpdateColumns(ctx context.Context, id string, columns map[string]interface{}) error {
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(columns).Error
+	if err != nil {
+		return fmt.Errorf("更新用户失败: %w", err)
+	}
+	return nil
+}
+
 // Delete 删除用户（软删除）
 func (r *userRepository) Delete(ctx context.Context, id string) error {
 	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.User{}).Error; err != nil {
@@ -80,3 +196,51 @@ func (r *userRepository) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// Restore 恢复已软删除的用户
+func (r *userRepository) Restore(ctx context.Context, id string) error {
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("id = ?", id).
+		UpdateColumn("deleted_at", nil).Error
+	if err != nil {
+		return fmt.Errorf("恢复用户失败: %w", err)
+	}
+	return nil
+}
+
+// PurgeUnverified 永久删除在 before 之前注册且仍未验证邮箱的用户及其角色关联
+// 使用硬删除释放邮箱唯一索引，以便用户重新注册
+func (r *userRepository) PurgeUnverified(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := tx.Model(&models.User{}).
+			Where("status = ? AND created_at < ?", models.UserStatusPendingVerification, before).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("清理未验证用户失败: %w", err)
+	}
+	return purged, nil
+}
+
+// escapeLike 转义 LIKE 查询中的通配符，配合 ESCAPE '!' 使用
+// 不使用反斜杠作为转义符，因为各数据库对字符串字面量中反斜杠的处理不一致
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
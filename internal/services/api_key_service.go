@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix 明文密钥的固定前缀，便于在日志和代码仓库中识别泄露的密钥
+	apiKeyPrefix = "mgf"
+	// apiKeyTouchInterval 最后使用时间的最小更新间隔，避免每次请求都写数据库
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey API Key 无效、已删除或已过期
+var ErrInvalidAPIKey = errors.New("API Key 无效或已过期")
+
+// APIKeyService API Key 服务接口
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID string, req dto.CreateAPIKeyRequest) (*models.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]*models.APIKey, error)
+	GetAPIKey(ctx context.Context, userID string, id uint) (*models.APIKey, error)
+	UpdateAPIKey(ctx context.Context, userID string, id uint, req dto.UpdateAPIKeyRequest) (*models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID string, id uint) error
+	AuthenticateAPIKey(ctx context.Context, rawKey string) (*utils.UserInfo, []string, error)
+}
+
+// apiKeyService API Key 服务实现
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+}
+
+// NewAPIKeyService 创建 API Key 服务实例
+func NewAPIKeyService(db *gorm.DB) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: repositories.NewAPIKeyRepository(db),
+		userRepo:   repositories.NewUserRepository(db),
+		roleRepo:   repositories.NewRoleRepository(db),
+	}
+}
+
+// CreateAPIKey 创建 API Key，返回记录和只展示一次的明文密钥
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, userID string, req dto.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	if err := validateScopes(req.Scopes); err != nil {
+		return nil, "", err
+	}
+
+	prefix, secret, rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		UserID:     userID,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     req.Scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+// ListAPIKeys 获取用户的 API Key 列表
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userID string) ([]*models.APIKey, error) {
+	return s.apiKeyRepo.ListByUser(ctx, userID)
+}
+
+// GetAPIKey 获取用户的单个 API Key
+func (s *apiKeyService) GetAPIKey(ctx context.Context, userID string, id uint) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.FindByIDAndUser(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("API Key 不存在")
+	}
+	return key, nil
+}
+
+// UpdateAPIKey 更新 API Key 的名称和权限范围
+func (s *apiKeyService) UpdateAPIKey(ctx context.Context, userID string, id uint, req dto.UpdateAPIKeyRequest) (*models.APIKey, error) {
+	key, err := s.GetAPIKey(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		key.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		if err := validateScopes(req.Scopes); err != nil {
+			return nil, err
+		}
+		key.Scopes = req.Scopes
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// DeleteAPIKey 删除 API Key，删除后立即失效
+func (s *apiKeyService) DeleteAPIKey(ctx context.Context, userID string, id uint) error {
+	deleted, err := s.apiKeyRepo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("API Key 不存在")
+	}
+	return nil
+}
+
+// AuthenticateAPIKey 校验 API Key，返回所属用户信息与权限范围
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*utils.UserInfo, []string, error) {
+	prefix, secret, ok := parseAPIKey(rawKey)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || key.IsExpired() {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive() {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	roles, err := s.roleRepo.GetUserRoleNames(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			logger.Warn("更新 API Key 使用时间失败", zap.Uint("api_key_id", key.ID), zap.Error(err))
+		}
+	}
+
+	return &utils.UserInfo{
+		Id:          user.ID,
+		DisplayName: user.Name,
+		Email:       user.EmailAddress(),
+		Phone:       user.PhoneNumber(),
+		Avatar:      user.Avatar,
+		Roles:       roles,
+	}, key.Scopes, nil
+}
+
+// generateAPIKey 生成 API Key，格式为 mgf_<prefix>_<secret>
+func generateAPIKey() (prefix string, secret string, rawKey string, err error) {
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	secret = base64.RawURLEncoding.EncodeToString(secretBytes)
+	return prefix, secret, apiKeyPrefix + "_" + prefix + "_" + secret, nil
+}
+
+// parseAPIKey 拆分明文 API Key，secret 中可能包含下划线，因此只拆分前两段
+func parseAPIKey(rawKey string) (prefix string, secret string, ok bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// hashAPIKeySecret 密钥为高熵随机值，使用 SHA-256 摘要即可，无需慢哈希
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		valid := false
+		for _, allowed := range models.APIKeyScopes {
+			if scope == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("不支持的权限范围: %s", scope)
+		}
+	}
+	return nil
+}
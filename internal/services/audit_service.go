@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/internal/task"
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// auditExportBatchSize 导出审计事件时每批读取的条数
+const auditExportBatchSize = 500
+
+// auditCSVHeader 审计事件导出文件的表头
+var auditCSVHeader = []string{"id", "created_at", "type", "outcome", "actor_id", "target_id", "subject", "ip", "user_agent", "request_id", "detail"}
+
+// AuditService 安全审计服务接口
+type AuditService interface {
+	Record(ctx context.Context, event models.AuditEvent)
+	ListEvents(ctx context.Context, req dto.AuditEventListRequest) ([]*models.AuditEvent, int64, error)
+	ExportEvents(ctx context.Context, req dto.AuditEventListRequest, w io.Writer) error
+}
+
+// auditService 安全审计服务实现
+type auditService struct {
+	auditRepo repositories.AuditEventRepository
+}
+
+// defaultAudit 全局审计服务，由 InitAudit 初始化，供各服务和认证中间件记录事件
+var defaultAudit *auditService
+
+// NewAuditService 创建安全审计服务实例
+func NewAuditService(db *gorm.DB) AuditService {
+	return newAuditService(db)
+}
+
+func newAuditService(db *gorm.DB) *auditService {
+	return &auditService{
+		auditRepo: repositories.NewAuditEventRepository(db),
+	}
+}
+
+// InitAudit 初始化全局审计服务
+func InitAudit(db *gorm.DB) {
+	defaultAudit = newAuditService(db)
+}
+
+// RecordAudit 使用全局审计服务记录事件，未初始化时忽略
+func RecordAudit(ctx context.Context, event models.AuditEvent) {
+	if defaultAudit == nil {
+		return
+	}
+	defaultAudit.Record(ctx, event)
+}
+
+// Record 记录审计事件
+// 请求 ID、客户端 IP 和 User-Agent 从请求上下文中补全，事件通过队列异步写入；
+// 队列未启用或入队失败时同步写入数据库。审计失败只记录日志，不影响业务操作
+func (s *auditService) Record(ctx context.Context, event models.AuditEvent) {
+	if event.Outcome == "" {
+		event.Outcome = models.AuditOutcomeSuccess
+	}
+	if event.ActorID == "" {
+		event.ActorID = utils.ContextString(ctx, utils.ContextKeyUserID)
+	}
+	if event.IP == "" {
+		event.IP = utils.ContextString(ctx, utils.ContextKeyClientIP)
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = utils.ContextString(ctx, utils.ContextKeyUserAgent)
+	}
+	if event.RequestID == "" {
+		event.RequestID = utils.ContextString(ctx, utils.ContextKeyRequestID)
+	}
+	event.Subject = truncate(event.Subject, 255)
+	event.UserAgent = truncate(event.UserAgent, 512)
+	event.Detail = truncate(event.Detail, 500)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if _, err := task.EnqueueAuditEventTask(event); err == nil {
+		return
+	}
+
+	// 请求结束后上下文会被取消，同步写入不跟随请求的取消信号
+	if err := s.auditRepo.Create(context.WithoutCancel(ctx), &event); err != nil {
+		logger.Warn("记录审计事件失败",
+			zap.String("type", event.Type),
+			zap.String("outcome", event.Outcome),
+			zap.String("target_id", event.TargetID),
+			zap.Error(err))
+	}
+}
+
+// ListEvents 按条件分页查询审计事件，分页参数由处理器补全默认值
+func (s *auditService) ListEvents(ctx context.Context, req dto.AuditEventListRequest) ([]*models.AuditEvent, int64, error) {
+	return s.auditRepo.List(ctx, auditEventQuery(req), (req.Page-1)*req.PageSize, req.PageSize)
+}
+
+// ExportEvents 将符合条件的审计事件以 CSV 格式写入 w，分批读取以控制内存占用
+func (s *auditService) ExportEvents(ctx context.Context, req dto.AuditEventListRequest, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.auditRepo.Each(ctx, auditEventQuery(req), auditExportBatchSize, func(events []*models.AuditEvent) error {
+		for _, event := range events {
+			record := []string{
+				strconv.FormatUint(event.ID, 10),
+				event.CreatedAt.Format(time.RFC3339),
+				event.Type,
+				event.Outcome,
+				event.ActorID,
+				event.TargetID,
+				csvSafe(event.Subject),
+				event.IP,
+				csvSafe(event.UserAgent),
+				csvSafe(event.RequestID),
+				csvSafe(event.Detail),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// auditEventQuery 将查询参数转换为仓储查询条件
+func auditEventQuery(req dto.AuditEventListRequest) repositories.AuditEventQuery {
+	query := repositories.AuditEventQuery{
+		Type:     req.Type,
+		Outcome:  req.Outcome,
+		ActorID:  req.ActorID,
+		TargetID: req.TargetID,
+		IP:       req.IP,
+		From:     req.From,
+	}
+	if req.To != nil {
+		// 结束日期包含当天
+		end := req.To.AddDate(0, 0, 1)
+		query.To = &end
+	}
+	return query
+}
+
+// csvSafe 防止 CSV 注入：以公式字符开头的用户可控内容在电子表格中打开时会被当作公式执行
+func csvSafe(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + value
+	}
+	return value
+}
+
+// auditEvent 根据操作结果构造审计事件，失败时将错误信息记为说明
+func auditEvent(eventType string, targetID string, err error) models.AuditEvent {
+	event := models.AuditEvent{
+		Type:     eventType,
+		Outcome:  models.AuditOutcomeSuccess,
+		TargetID: targetID,
+	}
+	if err != nil {
+		event.Outcome = models.AuditOutcomeFailure
+		event.Detail = err.Error()
+	}
+	return event
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLoginLocked 连续登录失败次数过多，账号或 IP 被临时锁定
+	ErrLoginLocked = errors.New("登录失败次数过多，请稍后再试")
+	// ErrMFALocked 两步验证连续失败次数过多，用户的第二因素校验被临时锁定
+	ErrMFALocked = errors.New("两步验证失败次数过多，请稍后再试")
+)
+
+// defaultMaxMFAFailures 未配置时两步验证的失败次数上限
+const defaultMaxMFAFailures = 5
+
+// loginThrottle 登录防暴力破解：按账号和 IP 统计失败次数，逐次增加响应延迟，达到上限后临时锁定
+type loginThrottle struct {
+	maxAccountFailures int
+	maxIPFailures      int
+	maxMFAFailures     int
+	window             time.Duration
+	lockout            time.Duration
+	delayAfter         int
+	baseDelay          time.Duration
+	maxDelay           time.Duration
+}
+
+// newLoginThrottle 根据配置创建登录限制器，未配置的时长使用默认值
+func newLoginThrottle(cfg config.Login) *loginThrottle {
+	t := &loginThrottle{
+		maxAccountFailures: cfg.MaxAccountFailures,
+		maxIPFailures:      cfg.MaxIPFailures,
+		maxMFAFailures:     cfg.MaxMFAFailures,
+		window:             time.Duration(cfg.FailureWindow) * time.Minute,
+		lockout:            time.Duration(cfg.LockoutDuration) * time.Minute,
+		delayAfter:         cfg.DelayAfter,
+		baseDelay:          time.Duration(cfg.BaseDelay) * time.Millisecond,
+		maxDelay:           time.Duration(cfg.MaxDelay) * time.Millisecond,
+	}
+	if t.window <= 0 {
+		t.window = 15 * time.Minute
+	}
+	if t.lockout <= 0 {
+		t.lockout = 15 * time.Minute
+	}
+	if t.maxMFAFailures <= 0 {
+		t.maxMFAFailures = defaultMaxMFAFailures
+	}
+	return t
+}
+
+// Check 检查账号和 IP 是否处于锁定状态，未锁定时按已有失败次数延迟响应
+func (t *loginThrottle) Check(ctx context.Context, email string, ip string) error {
+	accountKey := accountFailuresKey(email)
+	if t.locked(ctx, accountKey, zap.String("email", email)) {
+		return ErrLoginLocked
+	}
+	failures := t.count(ctx, accountKey)
+	if ip != "" {
+		ipKey := ipFailuresKey(ip)
+		if t.locked(ctx, ipKey, zap.String("ip", ip)) {
+			return ErrLoginLocked
+		}
+		failures = max(failures, t.count(ctx, ipKey))
+	}
+
+	return t.delay(ctx, failures)
+}
+
+// Fail 记录一次登录失败，达到上限时锁定账号或 IP
+func (t *loginThrottle) Fail(ctx context.Context, email string, ip string) {
+	t.fail(ctx, accountFailuresKey(email), t.maxAccountFailures, zap.String("email", email), zap.String("ip", ip))
+	if ip != "" {
+		t.fail(ctx, ipFailuresKey(ip), t.maxIPFailures, zap.String("ip", ip))
+	}
+}
+
+// Succeed 登录成功后清除账号的失败记录，IP 的失败记录保留至窗口过期
+func (t *loginThrottle) Succeed(ctx context.Context, email string) {
+	key := accountFailuresKey(email)
+	_ = cache.Forget(ctx, key)
+	_ = cache.Forget(ctx, lockKey(key))
+}
+
+// Unlock 主动解除账号锁定（如通过邮箱验证码重置密码后）
+func (t *loginThrottle) Unlock(ctx context.Context, email string) {
+	key := accountFailuresKey(email)
+	if until, ok := t.pullLock(ctx, key); ok && until.After(time.Now()) {
+		logger.Info("账号登录锁定已解除", zap.String("email", email), zap.String("reason", "reset"))
+	}
+	_ = cache.Forget(ctx, key)
+}
+
+// CheckMFA 检查用户的两步验证是否处于锁定状态
+// 按用户 ID 计数，不受密码登录成功清除账号失败记录的影响；缓存不可用时无法判断，视为锁定
+func (t *loginThrottle) CheckMFA(ctx context.Context, userID string) error {
+	key := mfaFailuresKey(userID)
+	locked, err := t.lockState(ctx, key, zap.String("user_id", userID))
+	if err != nil {
+		logger.Warn("读取两步验证锁定状态失败", zap.String("user_id", userID), zap.Error(err))
+		return ErrMFALocked
+	}
+	if locked {
+		return ErrMFALocked
+	}
+	return nil
+}
+
+// FailMFA 记录一次两步验证失败，返回是否已达到上限；记录失败时同样视为达到上限
+func (t *loginThrottle) FailMFA(ctx context.Context, userID string) bool {
+	return t.fail(ctx, mfaFailuresKey(userID), t.maxMFAFailures, zap.String("user_id", userID))
+}
+
+// SucceedMFA 两步验证通过后清除用户的失败记录
+func (t *loginThrottle) SucceedMFA(ctx context.Context, userID string) {
+	key := mfaFailuresKey(userID)
+	_ = cache.Forget(ctx, key)
+	_ = cache.Forget(ctx, lockKey(key))
+}
+
+// locked 判断是否处于锁定期，读取缓存失败时视为未锁定，锁定到期后清除记录并记录解锁日志
+// 到期的锁定记录通过 Pull 取出，多个实例同时检查时只记录一次解锁日志
+func (t *loginThrottle) locked(ctx context.Context, key string, subject zap.Field) bool {
+	locked, _ := t.lockState(ctx, key, subject)
+	return locked
+}
+
+// lockState 判断是否处于锁定期，锁定到期后清除记录并记录解锁日志；没有锁定记录以外的缓存错误原样返回
+// 到期的锁定记录通过 Pull 取出，多个实例同时检查时只记录一次解锁日志
+func (t *loginThrottle) lockState(ctx context.Context, key string, subject zap.Field) (bool, error) {
+	var until time.Time
+	if err := cache.GetObject(ctx, lockKey(key), &until); err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return false, nil
+		}
+		return false, err
+	}
+	if time.Now().Before(until) {
+		return true, nil
+	}
+
+	if _, ok := t.pullLock(ctx, key); ok {
+		_ = cache.Forget(ctx, key)
+		logger.Info("登录锁定已到期解除", subject)
+	}
+	return false, nil
+}
+
+// fail 原子地累加失败次数，达到上限时写入锁定截止时间，返回是否已达到上限（计数失败时返回 true）
+// 失败次数在窗口内从第一次失败起计算；并发的失败请求中只有第一个写入锁定记录
+func (t *loginThrottle) fail(ctx context.Context, key string, limit int, fields ...zap.Field) bool {
+	failures, err := cache.Increment(ctx, key, 1, t.window)
+	if err != nil {
+		logger.Warn("记录登录失败次数失败", append(fields, zap.Error(err))...)
+		return true
+	}
+	if limit <= 0 || failures < int64(limit) {
+		return false
+	}
+
+	until := time.Now().Add(t.lockout)
+	data, err := cache.SerializeObject(until)
+	if err != nil {
+		return true
+	}
+	// 保留记录到锁定期之后，以便到期时记录解锁日志
+	added, err := cache.Add(ctx, lockKey(key), data, t.lockout+t.window)
+	if err != nil {
+		logger.Warn("记录登录锁定失败", append(fields, zap.Error(err))...)
+		return true
+	}
+	if added {
+		logger.Warn("登录失败次数过多，已临时锁定",
+			append(fields, zap.Int64("failures", failures), zap.Time("locked_until", until))...)
+	}
+	return true
+}
+
+// delay 失败次数超过阈值后按指数递增延迟响应，上限为 maxDelay
+func (t *loginThrottle) delay(ctx context.Context, failures int) error {
+	if t.baseDelay <= 0 || failures < t.delayAfter || failures == 0 {
+		return nil
+	}
+
+	d := t.baseDelay
+	for i := t.delayAfter; i < failures && (t.maxDelay <= 0 || d < t.maxDelay); i++ {
+		d *= 2
+	}
+	if t.maxDelay > 0 && d > t.maxDelay {
+		d = t.maxDelay
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// count 读取失败次数，不存在时返回 0
+func (t *loginThrottle) count(ctx context.Context, key string) int {
+	var n int
+	_ = cache.GetObject(ctx, key, &n)
+	return n
+}
+
+// pullLock 取出并删除锁定记录，返回锁定截止时间
+func (t *loginThrottle) pullLock(ctx context.Context, key string) (time.Time, bool) {
+	var until time.Time
+	data, err := cache.Pull(ctx, lockKey(key))
+	if err != nil || cache.DeserializeObject(data, &until) != nil {
+		return until, false
+	}
+	return until, true
+}
+
+// accountFailuresKey 账号失败记录的缓存键，邮箱不区分大小写
+func accountFailuresKey(email string) string {
+	return fmt.Sprintf("login:failures:account:%s", strings.ToLower(email))
+}
+
+// ipFailuresKey IP 失败记录的缓存键
+func ipFailuresKey(ip string) string {
+	return fmt.Sprintf("login:failures:ip:%s", ip)
+}
+
+// mfaFailuresKey 用户两步验证失败记录的缓存键
+func mfaFailuresKey(userID string) string {
+	return fmt.Sprintf("login:failures:mfa:%s", userID)
+}
+
+// lockKey 锁定记录的缓存键，与失败次数分开保存
+func lockKey(key string) string {
+	return key + ":lock"
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// newTestCache 使用本地缓存初始化全局缓存，服务层的限流与验证码都基于全局缓存
+func newTestCache(t *testing.T) {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+
+	cfg := &config.Config{
+		LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+	}
+	if err := cache.Init(cfg); err != nil {
+		t.Fatalf("初始化缓存失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+}
+
+func newTestThrottle(maxAccount, maxIP int, lockout time.Duration) *loginThrottle {
+	return &loginThrottle{
+		maxAccountFailures: maxAccount,
+		maxIPFailures:      maxIP,
+		window:             time.Minute,
+		lockout:            lockout,
+	}
+}
+
+func TestLoginThrottleAccountLockout(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(3, 0, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		throttle.Fail(ctx, "a@example.com", "")
+	}
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Fatalf("未达到上限时 Check = %v, 期望 nil", err)
+	}
+
+	throttle.Fail(ctx, "a@example.com", "")
+	// 邮箱不区分大小写
+	if err := throttle.Check(ctx, "A@Example.com", ""); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("达到上限后 Check = %v, 期望 ErrLoginLocked", err)
+	}
+	if err := throttle.Check(ctx, "b@example.com", ""); err != nil {
+		t.Errorf("其他账号 Check = %v, 期望 nil", err)
+	}
+
+	throttle.Unlock(ctx, "a@example.com")
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Errorf("解除锁定后 Check = %v, 期望 nil", err)
+	}
+	if n := throttle.count(ctx, accountFailuresKey("a@example.com")); n != 0 {
+		t.Errorf("解除锁定后失败次数 = %d, 期望 0", n)
+	}
+}
+
+func TestLoginThrottleIPLockout(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(0, 2, time.Minute)
+
+	throttle.Fail(ctx, "a@example.com", "10.0.0.1")
+	throttle.Fail(ctx, "b@example.com", "10.0.0.1")
+
+	if err := throttle.Check(ctx, "c@example.com", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("IP 达到上限后 Check = %v, 期望 ErrLoginLocked", err)
+	}
+	if err := throttle.Check(ctx, "c@example.com", "10.0.0.2"); err != nil {
+		t.Errorf("其他 IP Check = %v, 期望 nil", err)
+	}
+	// 登录成功只清除账号的失败记录，IP 仍处于锁定状态
+	throttle.Succeed(ctx, "a@example.com")
+	if err := throttle.Check(ctx, "a@example.com", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("登录成功后 IP 仍应锁定, Check = %v", err)
+	}
+}
+
+func TestLoginThrottleLockExpires(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(2, 0, 100*time.Millisecond)
+
+	throttle.Fail(ctx, "a@example.com", "")
+	throttle.Fail(ctx, "a@example.com", "")
+	if err := throttle.Check(ctx, "a@example.com", ""); !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Check = %v, 期望 ErrLoginLocked", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Errorf("锁定到期后 Check = %v, 期望 nil", err)
+	}
+	// 到期解锁时清除失败次数，重新从 0 开始计算
+	if n := throttle.count(ctx, accountFailuresKey("a@example.com")); n != 0 {
+		t.Errorf("锁定到期后失败次数 = %d, 期望 0", n)
+	}
+	throttle.Fail(ctx, "a@example.com", "")
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Errorf("重新计数后 Check = %v, 期望 nil", err)
+	}
+}
+
+func TestLoginThrottleSucceedResetsAccount(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(3, 0, time.Minute)
+
+	throttle.Fail(ctx, "a@example.com", "")
+	throttle.Fail(ctx, "a@example.com", "")
+	throttle.Succeed(ctx, "a@example.com")
+	throttle.Fail(ctx, "a@example.com", "")
+
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Errorf("登录成功后失败次数应重新计算, Check = %v", err)
+	}
+}
+
+func TestLoginThrottleConcurrentFailures(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(5, 0, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			throttle.Fail(ctx, "a@example.com", "")
+		}()
+	}
+	wg.Wait()
+
+	if n := throttle.count(ctx, accountFailuresKey("a@example.com")); n != 20 {
+		t.Errorf("并发失败次数 = %d, 期望 20", n)
+	}
+	if err := throttle.Check(ctx, "a@example.com", ""); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Check = %v, 期望 ErrLoginLocked", err)
+	}
+}
+
+func TestLoginThrottleDelay(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newTestThrottle(0, 0, time.Minute)
+	throttle.delayAfter = 2
+	throttle.baseDelay = 50 * time.Millisecond
+	throttle.maxDelay = 100 * time.Millisecond
+
+	throttle.Fail(ctx, "a@example.com", "")
+	start := time.Now()
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil || time.Since(start) >= 50*time.Millisecond {
+		t.Errorf("未达到延迟阈值时 Check = %v, 耗时 %v, 期望立即返回", err, time.Since(start))
+	}
+
+	for i := 0; i < 5; i++ {
+		throttle.Fail(ctx, "a@example.com", "")
+	}
+	start = time.Now()
+	if err := throttle.Check(ctx, "a@example.com", ""); err != nil {
+		t.Fatalf("Check = %v, 期望 nil", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("延迟 = %v, 期望不超过上限 100ms", elapsed)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := throttle.Check(cancelCtx, "a@example.com", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后 Check = %v, 期望 context.Canceled", err)
+	}
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/utils"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// totpQRCodeSize 二维码图片边长（像素）
+	totpQRCodeSize = 256
+	// totpReplayWindow TOTP 验证码防重放的记录时间，覆盖校验时允许的前后时间窗口
+	totpReplayWindow = 90 * time.Second
+)
+
+// MFAService 两步验证服务接口
+type MFAService interface {
+	Enroll(ctx context.Context, userID string) (*dto.MFAEnrollResponse, error)
+	Confirm(ctx context.Context, userID string, code string) (*dto.RecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*dto.RecoveryCodesResponse, error)
+	VerifyCode(ctx context.Context, user *models.User, code string) (bool, error)
+}
+
+// mfaService 两步验证服务实现
+type mfaService struct {
+	userRepo         repositories.UserRepository
+	recoveryCodeRepo repositories.RecoveryCodeRepository
+	issuer           string
+	encryptor        *utils.Encryptor
+	encryptorErr     error
+	throttle         *loginThrottle
+}
+
+// NewMFAService 创建两步验证服务实例
+func NewMFAService(db *gorm.DB) MFAService {
+	cfg := config.GetConfig().MFA
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = "MyGoFrame"
+	}
+
+	// 加密密钥配置错误时只影响两步验证相关操作，不影响服务启动
+	encryptor, err := utils.NewEncryptor(cfg.EncryptionKey)
+	return &mfaService{
+		userRepo:         repositories.NewUserRepository(db),
+		recoveryCodeRepo: repositories.NewRecoveryCodeRepository(db),
+		issuer:           issuer,
+		encryptor:        encryptor,
+		encryptorErr:     err,
+		throttle:         newLoginThrottle(config.GetConfig().Login),
+	}
+}
+
+// Enroll 生成新的 TOTP 密钥，返回 otpauth URI 与二维码，需调用 Confirm 确认后才会启用
+func (s *mfaService) Enroll(ctx context.Context, userID string) (*dto.MFAEnrollResponse, error) {
+	if s.encryptorErr != nil {
+		return nil, fmt.Errorf("两步验证不可用: %w", s.encryptorErr)
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("已启用两步验证，请先停用后再重新绑定")
+	}
+
+	// 通过手机号注册的用户没有邮箱，使用手机号作为账户名
+	accountName := user.EmailAddress()
+	if accountName == "" {
+		accountName = user.PhoneNumber()
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: accountName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("生成 TOTP 密钥失败: %w", err)
+	}
+
+	encrypted, err := s.encryptor.Encrypt(key.Secret())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"totp_secret": encrypted}); err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = encrypted
+
+	qrCode, err := qrCodeDataURI(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:     key.Secret(),
+		OTPAuthURL: key.URL(),
+		QRCode:     qrCode,
+	}, nil
+}
+
+// Confirm 校验身份验证器生成的验证码，启用两步验证并返回恢复码
+func (s *mfaService) Confirm(ctx context.Context, userID string, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, errors.New("已启用两步验证")
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.New("请先绑定身份验证器")
+	}
+
+	valid, err := s.limitAttempts(ctx, user, func() (bool, error) {
+		return s.validateTOTP(ctx, user, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("验证码错误")
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"two_factor_enabled": true}); err != nil {
+		return nil, err
+	}
+	user.TwoFactorEnabled = true
+	s.forgetCachedUser(ctx, user.ID)
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable 停用两步验证，需要提供验证码或恢复码
+func (s *mfaService) Disable(ctx context.Context, userID string, code string) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return errors.New("未启用两步验证")
+	}
+
+	valid, err := s.VerifyCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New("验证码错误")
+	}
+
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"two_factor_enabled": false, "totp_secret": ""}); err != nil {
+		return err
+	}
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	s.forgetCachedUser(ctx, user.ID)
+
+	return s.recoveryCodeRepo.DeleteByUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部失效
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, errors.New("未启用两步验证")
+	}
+
+	valid, err := s.limitAttempts(ctx, user, func() (bool, error) {
+		return s.validateTOTP(ctx, user, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errors.New("验证码错误")
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyCode 校验 TOTP 验证码或恢复码，恢复码使用后立即失效
+// 连续失败达到上限后返回 ErrMFALocked，锁定期内不再校验
+func (s *mfaService) VerifyCode(ctx context.Context, user *models.User, code string) (bool, error) {
+	return s.limitAttempts(ctx, user, func() (bool, error) {
+		code = strings.TrimSpace(code)
+		if len(code) == 6 {
+			return s.validateTOTP(ctx, user, code)
+		}
+		return s.recoveryCodeRepo.Consume(ctx, user.ID, hashRecoveryCode(code))
+	})
+}
+
+// limitAttempts 按用户限制第二因素的校验次数，登录、启用、停用和重新生成恢复码共用同一失败计数
+// 达到上限的那次失败同样返回 ErrMFALocked，调用方据此作废临时令牌
+func (s *mfaService) limitAttempts(ctx context.Context, user *models.User, verify func() (bool, error)) (bool, error) {
+	if err := s.throttle.CheckMFA(ctx, user.ID); err != nil {
+		return false, err
+	}
+
+	valid, err := verify()
+	if err != nil {
+		return false, err
+	}
+	if !valid {
+		if s.throttle.FailMFA(ctx, user.ID) {
+			return false, ErrMFALocked
+		}
+		return false, nil
+	}
+	s.throttle.SucceedMFA(ctx, user.ID)
+	return true, nil
+}
+
+// validateTOTP 校验 TOTP 验证码，同一验证码在有效期内只能使用一次
+func (s *mfaService) validateTOTP(ctx context.Context, user *models.User, code string) (bool, error) {
+	if s.encryptorErr != nil {
+		return false, fmt.Errorf("两步验证不可用: %w", s.encryptorErr)
+	}
+
+	secret, err := s.encryptor.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+	if !totp.Validate(code, secret) {
+		return false, nil
+	}
+
+	replayKey := fmt.Sprintf("mfa:totp_used:%s:%s", user.ID, code)
+	// 原子占用，并发提交同一验证码时只有一个请求成功
+	added, err := cache.Add(ctx, replayKey, "1", totpReplayWindow)
+	if err != nil {
+		return false, err
+	}
+	return added, nil
+}
+
+// replaceRecoveryCodes 生成一组新的恢复码，只保存摘要，返回明文
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.recoveryCodeRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) findUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+	return user, nil
+}
+
+// forgetCachedUser 清除 GetUserByID 的用户缓存，使 two_factor_enabled 立即反映到资料接口
+func (s *mfaService) forgetCachedUser(ctx context.Context, userID string) {
+	_ = cache.Forget(ctx, fmt.Sprintf("user:%s", userID))
+}
+
+// generateRecoveryCode 生成形如 ABCDE-FGHIJ 的恢复码（50 位随机数）
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成恢复码失败: %w", err)
+	}
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// hashRecoveryCode 忽略大小写和分隔符，方便用户输入
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// qrCodeDataURI 生成可直接用于 <img src> 的二维码
+func qrCodeDataURI(key *otp.Key) (string, error) {
+	img, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return "", fmt.Errorf("生成二维码失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("生成二维码失败: %w", err)
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
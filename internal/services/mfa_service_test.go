@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/utils"
+
+	"github.com/pquerna/otp/totp"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建测试专用的内存 SQLite 数据库
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RecoveryCode{}); err != nil {
+		t.Fatalf("数据库迁移失败: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestMFAService 创建两步验证服务并绑定一个用户，返回用户与 TOTP 密钥
+func newTestMFAService(t *testing.T) (*mfaService, *models.User, string) {
+	t.Helper()
+	newTestCache(t)
+	db := newTestDB(t)
+
+	encryptor, err := utils.NewEncryptor("dGVzdC1tZmEtZW5jcnlwdGlvbi1rZXktMzJieXRlcyE=")
+	if err != nil {
+		t.Fatalf("创建加密器失败: %v", err)
+	}
+	s := &mfaService{
+		userRepo:         repositories.NewUserRepository(db),
+		recoveryCodeRepo: repositories.NewRecoveryCodeRepository(db),
+		issuer:           "test",
+		encryptor:        encryptor,
+		throttle:         newLoginThrottle(config.Login{MaxMFAFailures: 3}),
+	}
+
+	email := "mfa@example.com"
+	user := &models.User{ID: "user-1", Email: &email, Name: "mfa", Password: "x", Status: models.UserStatusActive}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	resp, err := s.Enroll(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("Enroll失败: %v", err)
+	}
+	return s, user, resp.Secret
+}
+
+// totpCode 生成相对当前时间偏移 periods 个周期的验证码，均在校验允许的时间窗口内
+func totpCode(t *testing.T, secret string, periods int) string {
+	t.Helper()
+	code, err := totp.GenerateCode(secret, time.Now().Add(time.Duration(periods)*30*time.Second))
+	if err != nil {
+		t.Fatalf("生成验证码失败: %v", err)
+	}
+	return code
+}
+
+// reload 重新读取用户，获取启用或停用后的状态
+func reload(t *testing.T, s *mfaService, id string) *models.User {
+	t.Helper()
+	user, err := s.userRepo.FindByID(context.Background(), id)
+	if err != nil || user == nil {
+		t.Fatalf("读取用户失败: %v", err)
+	}
+	return user
+}
+
+func TestMFAConfirmValidatesTOTP(t *testing.T) {
+	s, user, secret := newTestMFAService(t)
+	ctx := context.Background()
+
+	if _, err := s.Confirm(ctx, user.ID, wrongCode(totpCode(t, secret, 0))); err == nil {
+		t.Fatal("错误的验证码不应启用两步验证")
+	}
+	resp, err := s.Confirm(ctx, user.ID, totpCode(t, secret, 0))
+	if err != nil {
+		t.Fatalf("Confirm失败: %v", err)
+	}
+	if len(resp.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("恢复码数量 = %d, 期望 %d", len(resp.RecoveryCodes), recoveryCodeCount)
+	}
+	if !reload(t, s, user.ID).TwoFactorEnabled {
+		t.Error("Confirm 后应启用两步验证")
+	}
+}
+
+func TestMFARejectsReplayedTOTP(t *testing.T) {
+	s, user, secret := newTestMFAService(t)
+	ctx := context.Background()
+
+	code := totpCode(t, secret, 0)
+	if _, err := s.Confirm(ctx, user.ID, code); err != nil {
+		t.Fatalf("Confirm失败: %v", err)
+	}
+	user = reload(t, s, user.ID)
+
+	if valid, err := s.VerifyCode(ctx, user, code); err != nil || valid {
+		t.Errorf("重复使用的验证码 VerifyCode = %v, %v, 期望 false", valid, err)
+	}
+	if valid, err := s.VerifyCode(ctx, user, totpCode(t, secret, 1)); err != nil || !valid {
+		t.Errorf("新的验证码 VerifyCode = %v, %v, 期望 true", valid, err)
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	s, user, secret := newTestMFAService(t)
+	ctx := context.Background()
+
+	resp, err := s.Confirm(ctx, user.ID, totpCode(t, secret, 0))
+	if err != nil {
+		t.Fatalf("Confirm失败: %v", err)
+	}
+	user = reload(t, s, user.ID)
+
+	code := resp.RecoveryCodes[0]
+	if valid, err := s.VerifyCode(ctx, user, code); err != nil || !valid {
+		t.Fatalf("恢复码 VerifyCode = %v, %v, 期望 true", valid, err)
+	}
+	if valid, _ := s.VerifyCode(ctx, user, code); valid {
+		t.Error("恢复码使用后不应再次通过校验")
+	}
+	// 忽略大小写和分隔符
+	lenient := strings.ToLower(strings.ReplaceAll(resp.RecoveryCodes[1], "-", ""))
+	if valid, err := s.VerifyCode(ctx, user, lenient); err != nil || !valid {
+		t.Errorf("小写无分隔符的恢复码 VerifyCode = %v, %v, 期望 true", valid, err)
+	}
+
+	// 重新生成后旧恢复码全部失效
+	if _, err := s.RegenerateRecoveryCodes(ctx, user.ID, totpCode(t, secret, 1)); err != nil {
+		t.Fatalf("RegenerateRecoveryCodes失败: %v", err)
+	}
+	if valid, _ := s.VerifyCode(ctx, user, resp.RecoveryCodes[2]); valid {
+		t.Error("重新生成后旧恢复码不应通过校验")
+	}
+}
+
+func TestMFALockoutAfterFailures(t *testing.T) {
+	s, user, secret := newTestMFAService(t)
+	ctx := context.Background()
+
+	resp, err := s.Confirm(ctx, user.ID, totpCode(t, secret, -1))
+	if err != nil {
+		t.Fatalf("Confirm失败: %v", err)
+	}
+	user = reload(t, s, user.ID)
+
+	// 上限为 3 次，达到上限的那次失败即返回 ErrMFALocked
+	for i := 0; i < 2; i++ {
+		if valid, err := s.VerifyCode(ctx, user, "AAAAA-AAAAA"); err != nil || valid {
+			t.Fatalf("第 %d 次失败 VerifyCode = %v, %v", i+1, valid, err)
+		}
+	}
+	if _, err := s.VerifyCode(ctx, user, "AAAAA-AAAAA"); !errors.Is(err, ErrMFALocked) {
+		t.Fatalf("达到上限时 VerifyCode = %v, 期望 ErrMFALocked", err)
+	}
+
+	// 锁定期内正确的验证码和恢复码同样被拒绝，停用和重新生成恢复码共用同一计数
+	if _, err := s.VerifyCode(ctx, user, totpCode(t, secret, 0)); !errors.Is(err, ErrMFALocked) {
+		t.Errorf("锁定后 VerifyCode = %v, 期望 ErrMFALocked", err)
+	}
+	if err := s.Disable(ctx, user.ID, resp.RecoveryCodes[0]); !errors.Is(err, ErrMFALocked) {
+		t.Errorf("锁定后 Disable = %v, 期望 ErrMFALocked", err)
+	}
+	if _, err := s.RegenerateRecoveryCodes(ctx, user.ID, totpCode(t, secret, 0)); !errors.Is(err, ErrMFALocked) {
+		t.Errorf("锁定后 RegenerateRecoveryCodes = %v, 期望 ErrMFALocked", err)
+	}
+	if !reload(t, s, user.ID).TwoFactorEnabled {
+		t.Error("锁定期内不应停用两步验证")
+	}
+	// 锁定期内提交的恢复码不会被消耗
+	s.throttle.SucceedMFA(ctx, user.ID)
+	if err := s.Disable(ctx, user.ID, resp.RecoveryCodes[0]); err != nil {
+		t.Errorf("解除锁定后 Disable = %v, 期望 nil", err)
+	}
+}
+
+func TestMFASuccessResetsFailures(t *testing.T) {
+	s, user, secret := newTestMFAService(t)
+	ctx := context.Background()
+
+	if _, err := s.Confirm(ctx, user.ID, totpCode(t, secret, -1)); err != nil {
+		t.Fatalf("Confirm失败: %v", err)
+	}
+	user = reload(t, s, user.ID)
+
+	_, _ = s.VerifyCode(ctx, user, "AAAAA-AAAAA")
+	_, _ = s.VerifyCode(ctx, user, "AAAAA-AAAAA")
+	if valid, err := s.VerifyCode(ctx, user, totpCode(t, secret, 0)); err != nil || !valid {
+		t.Fatalf("VerifyCode = %v, %v, 期望 true", valid, err)
+	}
+	if valid, err := s.VerifyCode(ctx, user, "AAAAA-AAAAA"); err != nil || valid {
+		t.Errorf("成功后失败次数应重新计算, VerifyCode = %v, %v", valid, err)
+	}
+}
+
+func TestMFACheckFailsClosedOnCacheError(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	throttle := newLoginThrottle(config.Login{})
+
+	// 锁定记录无法读取时无法判断是否锁定，按锁定处理
+	if err := cache.Put(ctx, lockKey(mfaFailuresKey("user-1")), "invalid", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	if err := throttle.CheckMFA(ctx, "user-1"); !errors.Is(err, ErrMFALocked) {
+		t.Errorf("缓存出错时 CheckMFA = %v, 期望 ErrMFALocked", err)
+	}
+	// 计数器无法累加时同样视为达到上限
+	if err := cache.Put(ctx, mfaFailuresKey("user-2"), "invalid", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	if !throttle.FailMFA(ctx, "user-2") {
+		t.Error("计数失败时 FailMFA 应返回 true")
+	}
+}
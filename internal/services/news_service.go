@@ -3,16 +3,38 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"mygoframe/internal/dto"
 	"mygoframe/internal/models"
 	"mygoframe/internal/repositories"
+	"mygoframe/pkg/authz"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/logger"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// 快讯列表缓存，每页一个键，发布或更新快讯时按标签整体清除
+const (
+	newsListCacheTag = "news:list"
+	newsListCacheTTL = 5 * time.Minute
+)
+
+// newsListPage 缓存的一页快讯列表
+type newsListPage struct {
+	List  []*models.News `json:"list"`
+	Total int64          `json:"total"`
+}
+
 type NewsService interface {
 	GetNewsByID(ctx context.Context, id uint) (*models.News, error)
 	GetNewsList(ctx context.Context, page, pageSize int) ([]*models.News, int64, error)
+	CreateNews(ctx context.Context, req dto.CreateNewsRequest) (*models.News, error)
+	UpdateNews(ctx context.Context, id uint, req dto.UpdateNewsRequest) (*models.News, error)
 }
 
 type newsService struct {
@@ -28,6 +50,11 @@ func (s *newsService) GetNewsByID(ctx context.Context, id uint) (*models.News, e
 	if err != nil {
 		return nil, errors.New("快讯不存在")
 	}
+
+	// 无权查看时同样返回不存在，避免泄露内部快讯
+	if err := authz.Authorize(ctx, "news:read", newsResource(news)); err != nil {
+		return nil, errors.New("快讯不存在")
+	}
 	return news, nil
 }
 
@@ -39,16 +66,129 @@ func (s *newsService) GetNewsList(ctx context.Context, page, pageSize int) ([]*m
 		pageSize = 10
 	}
 
-	total, err := s.repo.Count(ctx)
+	visibilities := s.readableVisibilities(ctx)
+
+	// 可见性组合不同的用户看到的列表不同，分别缓存
+	cacheKey := fmt.Sprintf("news:list:%s:%d:%d", strings.Join(visibilities, ","), page, pageSize)
+	lists := cache.Tags(newsListCacheTag)
+
+	var cached newsListPage
+	if err := lists.GetObject(ctx, cacheKey, &cached); err == nil {
+		return cached.List, cached.Total, nil
+	}
+
+	total, err := s.repo.CountByVisibility(ctx, visibilities)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * pageSize
-	list, err := s.repo.List(ctx, offset, pageSize)
+	list, err := s.repo.ListByVisibility(ctx, visibilities, offset, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	if err := lists.PutObject(ctx, cacheKey, newsListPage{List: list, Total: total}, newsListCacheTTL); err != nil {
+		logger.Warn("缓存快讯列表失败", zap.String("key", cacheKey), zap.Error(err))
+	}
 	return list, total, nil
 }
+
+// CreateNews 发布快讯，当前用户记为作者
+func (s *newsService) CreateNews(ctx context.Context, req dto.CreateNewsRequest) (*models.News, error) {
+	if err := authz.Authorize(ctx, "news:create", authz.NewResource("news", nil)); err != nil {
+		return nil, err
+	}
+
+	subject := authz.SubjectFromContext(ctx)
+	if subject == nil {
+		return nil, authz.ErrForbidden
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.NewsVisibilityPublic
+	}
+
+	news := &models.News{
+		Title:       req.Title,
+		Content:     req.Content,
+		Source:      req.Source,
+		Category:    req.Category,
+		IsImportant: req.IsImportant,
+		AuthorID:    subject.Id,
+		Visibility:  visibility,
+	}
+	if err := s.repo.Create(ctx, news); err != nil {
+		return nil, err
+	}
+	s.flushListCache(ctx)
+	return news, nil
+}
+
+// UpdateNews 更新快讯，是否允许由授权策略决定（例如作者只能编辑自己的快讯）
+func (s *newsService) UpdateNews(ctx context.Context, id uint, req dto.UpdateNewsRequest) (*models.News, error) {
+	news, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.New("快讯不存在")
+	}
+
+	if err := authz.Authorize(ctx, "news:update", newsResource(news)); err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		news.Title = *req.Title
+	}
+	if req.Content != nil {
+		news.Content = *req.Content
+	}
+	if req.Source != nil {
+		news.Source = *req.Source
+	}
+	if req.Category != nil {
+		news.Category = *req.Category
+	}
+	if req.IsImportant != nil {
+		news.IsImportant = *req.IsImportant
+	}
+	if req.Visibility != nil {
+		news.Visibility = *req.Visibility
+	}
+
+	if err := s.repo.Update(ctx, news); err != nil {
+		return nil, err
+	}
+	s.flushListCache(ctx)
+	return news, nil
+}
+
+// flushListCache 清除所有快讯列表缓存，不影响其他缓存
+func (s *newsService) flushListCache(ctx context.Context) {
+	if err := cache.Tags(newsListCacheTag).Flush(ctx); err != nil {
+		logger.Warn("清除快讯列表缓存失败", zap.Error(err))
+	}
+}
+
+// readableVisibilities 计算当前用户可以在列表中看到的可见性级别
+// 列表按可见性在数据库中过滤，保证分页与总数准确
+func (s *newsService) readableVisibilities(ctx context.Context) []string {
+	visibilities := make([]string, 0, 2)
+	for _, visibility := range []string{models.NewsVisibilityPublic, models.NewsVisibilityInternal} {
+		resource := authz.NewResource("news", map[string]interface{}{"visibility": visibility})
+		if authz.Authorize(ctx, "news:read", resource) == nil {
+			visibilities = append(visibilities, visibility)
+		}
+	}
+	return visibilities
+}
+
+// newsResource 构造快讯的授权资源属性
+func newsResource(news *models.News) authz.Resource {
+	return authz.NewResource("news", map[string]interface{}{
+		"id":         news.ID,
+		"author_id":  news.AuthorID,
+		"visibility": news.Visibility,
+		"category":   news.Category,
+	})
+}
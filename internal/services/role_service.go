@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// userPermissionsTTL 用户权限缓存时间，角色变更时会主动失效
+const userPermissionsTTL = 10 * time.Minute
+
+// RoleService 角色权限服务接口
+type RoleService interface {
+	EnsureDefaultRoles(ctx context.Context) error
+	ListRoles(ctx context.Context) ([]*models.Role, error)
+	CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.Role, error)
+	SetRolePermissions(ctx context.Context, roleID uint, permissions []string) (*models.Role, error)
+	AssignRole(ctx context.Context, userID string, roleName string) error
+	RevokeRole(ctx context.Context, userID string, roleName string) error
+	GetUserRoles(ctx context.Context, userID string) ([]string, error)
+	GetUserPermissions(ctx context.Context, userID string) ([]string, error)
+}
+
+// roleService 角色权限服务实现
+type roleService struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+	users    *userService
+}
+
+// NewRoleService 创建角色权限服务实例
+func NewRoleService(db *gorm.DB) RoleService {
+	return &roleService{
+		roleRepo: repositories.NewRoleRepository(db),
+		userRepo: repositories.NewUserRepository(db),
+		users:    newUserService(db),
+	}
+}
+
+// EnsureDefaultRoles 确保内置角色存在，在启动时调用
+func (s *roleService) EnsureDefaultRoles(ctx context.Context) error {
+	defaults := map[string]string{
+		models.RoleAdmin: "系统管理员",
+		models.RoleUser:  "普通用户",
+	}
+	for name, description := range defaults {
+		if _, err := s.roleRepo.FirstOrCreate(ctx, name, description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRoles 获取所有角色
+func (s *roleService) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return s.roleRepo.List(ctx)
+}
+
+// CreateRole 创建角色
+func (s *roleService) CreateRole(ctx context.Context, req dto.CreateRoleRequest) (*models.Role, error) {
+	existing, err := s.roleRepo.FindByName(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.New("角色已存在")
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	if len(req.Permissions) > 0 {
+		if err := s.roleRepo.ReplacePermissions(ctx, role, req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.roleRepo.FindByID(ctx, role.ID)
+}
+
+// SetRolePermissions 设置角色的权限集合，并使拥有该角色的用户权限缓存失效
+func (s *roleService) SetRolePermissions(ctx context.Context, roleID uint, permissions []string) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("角色不存在")
+	}
+
+	if err := s.roleRepo.ReplacePermissions(ctx, role, permissions); err != nil {
+		return nil, err
+	}
+
+	userIDs, err := s.roleRepo.GetUserIDsByRole(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		s.invalidateUserPermissions(ctx, userID)
+	}
+
+	return s.roleRepo.FindByID(ctx, role.ID)
+}
+
+// AssignRole 为用户分配角色
+// 新角色写入令牌声明需要重新登录或刷新令牌，权限校验则立即生效
+func (s *roleService) AssignRole(ctx context.Context, userID string, roleName string) error {
+	role, err := s.findUserAndRole(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AssignToUser(ctx, userID, role); err != nil {
+		return err
+	}
+
+	s.invalidateUserPermissions(ctx, userID)
+	s.auditRoleChange(ctx, userID, "assign", roleName)
+	return nil
+}
+
+// RevokeRole 移除用户的角色
+// 令牌中携带的角色无法原地更新，因此同时吊销该用户已签发的令牌和会话，使权限收回立即生效
+func (s *roleService) RevokeRole(ctx context.Context, userID string, roleName string) error {
+	role, err := s.findUserAndRole(ctx, userID, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.RemoveFromUser(ctx, userID, role); err != nil {
+		return err
+	}
+
+	s.invalidateUserPermissions(ctx, userID)
+	s.auditRoleChange(ctx, userID, "revoke", roleName)
+
+	return s.users.LogoutAll(ctx, userID)
+}
+
+// auditRoleChange 记录角色变更审计事件
+func (s *roleService) auditRoleChange(ctx context.Context, userID string, action string, roleName string) {
+	event := auditEvent(models.AuditEventRoleChange, userID, nil)
+	event.Detail = action + ": " + roleName
+	RecordAudit(ctx, event)
+}
+
+// GetUserRoles 获取用户的角色名称
+func (s *roleService) GetUserRoles(ctx context.Context, userID string) ([]string, error) {
+	return s.roleRepo.GetUserRoleNames(ctx, userID)
+}
+
+// GetUserPermissions 获取用户的权限名称，结果缓存在 pkg/cache 中
+func (s *roleService) GetUserPermissions(ctx context.Context, userID string) ([]string, error) {
+	return cache.GetOrLoad(ctx, userPermissionsKey(userID), userPermissionsTTL, func(ctx context.Context) ([]string, error) {
+		permissions, err := s.roleRepo.GetUserPermissionNames(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if permissions == nil {
+			// 缓存空列表，避免无权限用户每次都查询数据库
+			permissions = []string{}
+		}
+		return permissions, nil
+	})
+}
+
+func (s *roleService) findUserAndRole(ctx context.Context, userID string, roleName string) (*models.Role, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	role, err := s.roleRepo.FindByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("角色不存在")
+	}
+	return role, nil
+}
+
+func (s *roleService) invalidateUserPermissions(ctx context.Context, userID string) {
+	if err := cache.Forget(ctx, userPermissionsKey(userID)); err != nil {
+		logger.Warn("清除用户权限缓存失败", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+func userPermissionsKey(userID string) string {
+	return fmt.Sprintf("user_permissions:%s", userID)
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mygoframe/internal/dto"
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// ErrSessionRevoked 会话已被吊销
+var ErrSessionRevoked = errors.New("会话已失效，请重新登录")
+
+// SessionService 登录会话服务接口
+type SessionService interface {
+	ListSessions(ctx context.Context, userID string) ([]*models.Session, error)
+	RevokeSession(ctx context.Context, userID string, id string) error
+}
+
+// sessionService 登录会话服务实现
+// 会话与刷新令牌族一一对应，吊销会话即吊销令牌族，使该设备上的访问令牌和刷新令牌同时失效
+type sessionService struct {
+	sessionRepo repositories.SessionRepository
+	jwtUtil     *utils.JWTUtil
+}
+
+// NewSessionService 创建登录会话服务实例
+func NewSessionService(db *gorm.DB) SessionService {
+	return newSessionService(db)
+}
+
+func newSessionService(db *gorm.DB) *sessionService {
+	jwtUtil, _ := utils.GetJWTUtil()
+	return &sessionService{
+		sessionRepo: repositories.NewSessionRepository(db),
+		jwtUtil:     jwtUtil,
+	}
+}
+
+// ListSessions 获取用户当前有效的会话
+func (s *sessionService) ListSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	return s.sessionRepo.ListActiveByUser(ctx, userID)
+}
+
+// RevokeSession 吊销用户的某个会话（退出指定设备）
+func (s *sessionService) RevokeSession(ctx context.Context, userID string, id string) error {
+	session, err := s.sessionRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID || !session.IsActive() {
+		return errors.New("会话不存在")
+	}
+
+	if err := s.jwtUtil.RevokeFamily(ctx, session.ID); err != nil {
+		return fmt.Errorf("吊销令牌族失败: %w", err)
+	}
+	if err := s.sessionRepo.Revoke(ctx, session.ID, time.Now()); err != nil {
+		return err
+	}
+
+	event := auditEvent(models.AuditEventSessionRevoke, userID, nil)
+	event.Detail = session.Device
+	RecordAudit(ctx, event)
+	return nil
+}
+
+// record 登录时创建会话
+func (s *sessionService) record(ctx context.Context, userID string, tokens *utils.TokenPair, client dto.ClientInfo) error {
+	now := time.Now()
+	return s.sessionRepo.Save(ctx, &models.Session{
+		ID:         tokens.Family,
+		UserID:     userID,
+		Device:     sessionDevice(client),
+		UserAgent:  truncate(client.UserAgent, 512),
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  tokens.RefreshExpiresAt,
+	})
+}
+
+// check 刷新令牌前检查会话是否已被吊销，会话不存在（功能上线前签发的令牌）时视为有效
+func (s *sessionService) check(ctx context.Context, family string) (*models.Session, error) {
+	session, err := s.sessionRepo.FindByID(ctx, family)
+	if err != nil {
+		return nil, err
+	}
+	if session != nil && session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+	return session, nil
+}
+
+// touch 刷新令牌后更新会话的最后活跃时间与客户端信息，会话不存在时补建，已被吊销时返回 ErrSessionRevoked
+func (s *sessionService) touch(ctx context.Context, session *models.Session, userID string, tokens *utils.TokenPair, client dto.ClientInfo) error {
+	if session == nil {
+		return s.record(ctx, userID, tokens, client)
+	}
+
+	session.LastSeenAt = time.Now()
+	session.ExpiresAt = tokens.RefreshExpiresAt
+	session.IP = client.IP
+	if client.UserAgent != "" {
+		session.UserAgent = truncate(client.UserAgent, 512)
+	}
+	if client.Device != "" {
+		session.Device = sessionDevice(client)
+	}
+	touched, err := s.sessionRepo.Touch(ctx, session)
+	if err != nil {
+		return err
+	}
+	// 会话在检查之后被并发吊销
+	if !touched {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
+// revoke 登出时吊销会话
+func (s *sessionService) revoke(ctx context.Context, family string) error {
+	return s.sessionRepo.Revoke(ctx, family, time.Now())
+}
+
+// revokeAll 退出所有设备或修改密码时吊销用户的全部会话
+func (s *sessionService) revokeAll(ctx context.Context, userID string) error {
+	return s.sessionRepo.RevokeAllByUser(ctx, userID, time.Now())
+}
+
+// sessionDevice 会话的设备名称，客户端未提交时根据 User-Agent 推断
+func sessionDevice(client dto.ClientInfo) string {
+	if client.Device != "" {
+		return truncate(client.Device, 100)
+	}
+	return deviceFromUserAgent(client.UserAgent)
+}
+
+// deviceFromUserAgent 从 User-Agent 中粗略识别浏览器和操作系统，如 "Chrome on Windows"
+func deviceFromUserAgent(ua string) string {
+	if ua == "" {
+		return "未知设备"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	}
+
+	var os string
+	switch {
+	case strings.Contains(ua, "iPhone"):
+		os = "iPhone"
+	case strings.Contains(ua, "iPad"):
+		os = "iPad"
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return truncate(ua, 100)
+	}
+}
+
+// truncate 按字节截断字符串，保证不超过数据库字段长度
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "")
+}
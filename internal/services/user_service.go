@@ -1,337 +1,988 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"io"
 	"mygoframe/internal/dto"
 	"mygoframe/internal/models"
 	"mygoframe/internal/repositories"
+	"mygoframe/internal/task"
 	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/notify"
+	"mygoframe/pkg/storage"
 	"mygoframe/pkg/utils"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// ErrEmailNotVerified 注册后尚未验证邮箱
+var ErrEmailNotVerified = errors.New("邮箱尚未验证，请先点击邮件中的验证链接")
+
+// ErrAvatarTooLarge 上传的头像文件过大
+var ErrAvatarTooLarge = errors.New("头像文件过大")
+
+// errInvalidCredentials 登录失败的统一错误信息
+var errInvalidCredentials = errors.New("邮箱或密码错误")
+
+const (
+	// registerLockTTL 注册锁的有效期，执行期间自动续期
+	registerLockTTL = 10 * time.Second
+	// registerLockWait 同一账号并发注册时等待注册锁的最长时间
+	registerLockWait = 3 * time.Second
+)
+
 // UserService 用户服务接口
 type UserService interface {
 	Register(ctx context.Context, req dto.UserRegisterRequest) (*models.User, error)
+	VerifyEmail(ctx context.Context, token string) error
 	Login(ctx context.Context, req dto.UserLoginRequest) (*dto.UserLoginResponse, error)
-	RefreshToken(ctx context.Context, refreshToken string) (*dto.RefreshTokenResponse, error)
+	LoginWithSMS(ctx context.Context, req dto.SMSLoginRequest) (*dto.UserLoginResponse, error)
+	LoginWithEmail(ctx context.Context, req dto.EmailLoginRequest) (*dto.UserLoginResponse, error)
+	LoginWith2FA(ctx context.Context, req dto.LoginMFARequest) (*dto.UserLoginResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.RefreshTokenResponse, error)
 	Logout(ctx context.Context, accessToken string) error
+	LogoutAll(ctx context.Context, userID string) error
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	UpdateProfile(ctx context.Context, userID string, req dto.UpdateProfileRequest) (*models.User, error)
+	UpdateAvatar(ctx context.Context, userID string, r io.Reader) (*dto.AvatarResponse, error)
 	SendSMSCode(ctx context.Context, req dto.SendSMSCodeRequest) (*dto.SendSMSCodeResponse, error)
 	VerifySMSCode(ctx context.Context, req dto.VerifySMSCodeRequest) (*dto.VerifySMSCodeResponse, error)
 	SendEmailCode(ctx context.Context, req dto.SendEmailCodeRequest) (*dto.SendEmailCodeResponse, error)
 	VerifyEmailCode(ctx context.Context, req dto.VerifyEmailCodeRequest) (*dto.VerifyEmailCodeResponse, error)
+	ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) (*dto.SendEmailCodeResponse, error)
+	ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error
+	ChangePassword(ctx context.Context, userID string, req dto.ChangePasswordRequest) error
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repositories.UserRepository
-	jwtUtil  *utils.JWTUtil
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+	mfaService MFAService
+	sessions   *sessionService
+	jwtUtil    *utils.JWTUtil
+	throttle   *loginThrottle
+	codes      *codeManager
+	hasher     *utils.PasswordHasher
+	policy     *utils.PasswordPolicy
+	// dummyHash 账号不存在时用于比较的哈希，与真实密码哈希的参数相同
+	dummyHash string
+	// autoRegister 验证码登录时账号不存在是否自动注册
+	autoRegister bool
+	registerCfg  config.Register
+	avatarCfg    config.Avatar
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService(db *gorm.DB) UserService {
+	return newUserService(db)
+}
+
+func newUserService(db *gorm.DB) *userService {
 	jwtUtil, _ := utils.GetJWTUtil()
+	cfg := config.GetConfig()
+	loginCfg := cfg.Login
+
+	hasher, err := utils.NewPasswordHasher(cfg.Password)
+	if err != nil {
+		logger.Error("密码哈希配置无效，使用默认参数", zap.Error(err))
+		hasher, _ = utils.NewPasswordHasher(config.Password{})
+	}
+	policy, err := utils.NewPasswordPolicy(cfg.Password.Policy)
+	if err != nil {
+		logger.Warn("加载泄露密码列表失败", zap.Error(err))
+	}
+	dummyHash, _ := hasher.Hash(uuid.New().String())
+
 	return &userService{
-		userRepo: repositories.NewUserRepository(db),
-		jwtUtil:  jwtUtil,
+		userRepo:     repositories.NewUserRepository(db),
+		roleRepo:     repositories.NewRoleRepository(db),
+		mfaService:   NewMFAService(db),
+		sessions:     newSessionService(db),
+		jwtUtil:      jwtUtil,
+		throttle:     newLoginThrottle(loginCfg),
+		codes:        newCodeManager(cfg.VerifyCode),
+		hasher:       hasher,
+		policy:       policy,
+		dummyHash:    dummyHash,
+		registerCfg:  cfg.Register,
+		avatarCfg:    cfg.Avatar,
+		autoRegister: loginCfg.AutoRegister,
 	}
 }
 
 // Register 用户注册
 func (s *userService) Register(ctx context.Context, req dto.UserRegisterRequest) (*models.User, error) {
-	existingUser, err := s.userRepo.FindByEmail(req.Email) // FindByEmail暂时不传context，保持现状
+	var user *models.User
+	// 查重与创建在注册锁内执行，避免并发注册同一邮箱时都通过查重
+	err := withRegisterLock(ctx, "email", req.Email, func(ctx context.Context) error {
+		existingUser, err := s.userRepo.FindByEmail(req.Email) // FindByEmail暂时不传context，保持现状
+		if err != nil {
+			return err
+		}
+		if existingUser != nil {
+			return errors.New("邮箱已被注册")
+		}
+
+		status := models.UserStatusActive
+		if s.registerCfg.EmailVerification {
+			status = models.UserStatusPendingVerification
+		}
+
+		if err := s.policy.Validate(req.Password); err != nil {
+			return err
+		}
+		hashedPassword, err := s.hashPassword(req.Password)
+		if err != nil {
+			return err
+		}
+
+		user = &models.User{
+			ID:       uuid.New().String(),
+			Email:    &req.Email,
+			Password: hashedPassword,
+			Name:     req.Name,
+			Status:   status,
+		}
+		return s.createUser(ctx, user)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if existingUser != nil {
-		return nil, errors.New("邮箱已被注册")
+
+	welcome := task.WelcomeEmailPayload{UserID: user.ID, Email: req.Email, Name: user.Name}
+	if user.IsPendingVerification() {
+		welcome.VerifyURL, err = s.emailVerifyURL(user)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	user := &models.User{
-		ID:       uuid.New().String(),
-		Email:    req.Email,
-		Password: s.hashPassword(req.Password),
-		Name:     req.Name,
-		Status:   "active",
+	// 邮件发送失败不影响注册结果，未验证的用户可通过邮箱验证码登录完成验证
+	if err := task.DeliverWelcomeEmail(ctx, welcome); err != nil {
+		logger.Warn("欢迎邮件发送失败", zap.String("user_id", user.ID), zap.Error(err))
 	}
 
+	return user, nil
+}
+
+// VerifyEmail 使用邮件中的验证令牌激活账号，重复验证视为成功
+func (s *userService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.jwtUtil.ValidateToken(ctx, token, utils.TokenTypeEmailVerify)
+	if err != nil {
+		return errors.New("验证链接无效或已过期")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserInfo.Id)
+	if err != nil {
+		return err
+	}
+	// 令牌签发后邮箱被修改时旧链接失效
+	if user == nil || user.EmailAddress() != claims.UserInfo.Email {
+		return errors.New("验证链接无效或已过期")
+	}
+
+	if user.IsPendingVerification() {
+		if err := s.activate(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	// 验证链接只能使用一次
+	if err := s.jwtUtil.RevokeToken(ctx, claims); err != nil {
+		return fmt.Errorf("吊销令牌失败: %w", err)
+	}
+
+	return nil
+}
+
+// activate 将等待邮箱验证的用户设为正常状态
+func (s *userService) activate(ctx context.Context, user *models.User) error {
+	if err := s.userRepo.UpdateStatus(ctx, user.ID, models.UserStatusActive); err != nil {
+		return err
+	}
+	user.Status = models.UserStatusActive
+	_ = cache.Forget(ctx, fmt.Sprintf("user:%s", user.ID))
+	return nil
+}
+
+// emailVerifyURL 生成邮箱验证链接
+func (s *userService) emailVerifyURL(user *models.User) (string, error) {
+	ttl := time.Duration(s.registerCfg.VerifyTokenTTL) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	token, err := s.jwtUtil.GenerateEmailVerifyToken(user.ID, user.EmailAddress(), ttl)
+	if err != nil {
+		return "", fmt.Errorf("生成邮箱验证令牌失败: %w", err)
+	}
+
+	return s.registerCfg.VerifyURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// withRegisterLock 持有邮箱或手机号的注册锁时执行 fn，多个实例并发注册同一账号时查重与创建串行执行
+func withRegisterLock(ctx context.Context, kind string, identifier string, fn func(ctx context.Context) error) error {
+	name := fmt.Sprintf("register:%s:%s", kind, strings.ToLower(identifier))
+	err := cache.RunLocked(ctx, name, registerLockTTL, registerLockWait, fn)
+	if errors.Is(err, cache.ErrLockNotAcquired) {
+		return errors.New("注册请求处理中，请稍后再试")
+	}
+	return err
+}
+
+// createUser 保存新用户并分配默认角色
+func (s *userService) createUser(ctx context.Context, user *models.User) error {
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, err
+		return err
 	}
 
-	return user, nil
+	// 新用户默认获得普通用户角色
+	role, err := s.roleRepo.FindByName(ctx, models.RoleUser)
+	if err != nil {
+		return err
+	}
+	if role != nil {
+		if err := s.roleRepo.AssignToUser(ctx, user.ID, role); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Login 用户登录
 func (s *userService) Login(ctx context.Context, req dto.UserLoginRequest) (*dto.UserLoginResponse, error) {
+	user, resp, err := s.login(ctx, req)
+	s.auditLogin(ctx, "password", req.Email, user, resp, err)
+	return resp, err
+}
+
+// login 使用邮箱和密码登录，返回通过第一因素验证的用户用于审计
+// 账号不存在与密码错误返回相同的错误，避免通过登录接口探测账号是否存在
+func (s *userService) login(ctx context.Context, req dto.UserLoginRequest) (*models.User, *dto.UserLoginResponse, error) {
+	if err := s.throttle.Check(ctx, req.Email, req.IP); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email) // FindByEmail暂时不传context，保持现状
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		// 账号不存在时同样执行一次哈希比较，使响应时间与密码错误一致
+		s.verifyPassword(req.Password, s.dummyHash)
+		s.throttle.Fail(ctx, req.Email, req.IP)
+		return nil, nil, errInvalidCredentials
 	}
 
+	if !s.verifyPassword(req.Password, user.Password) {
+		s.throttle.Fail(ctx, req.Email, req.IP)
+		return user, nil, errInvalidCredentials
+	}
+	s.throttle.Succeed(ctx, req.Email)
+	s.rehashIfNeeded(ctx, user, req.Password)
+
+	if user.IsPendingVerification() {
+		return user, nil, ErrEmailNotVerified
+	}
 	if !user.IsActive() {
-		return nil, errors.New("用户已被禁用")
+		return user, nil, errors.New("用户已被禁用")
 	}
 
-	if !s.verifyPassword(req.Password, user.Password) {
-		return nil, errors.New("密码错误")
+	resp, err := s.completeLogin(ctx, user, req.ClientInfo)
+	return user, resp, err
+}
+
+// LoginWithSMS 使用短信验证码登录
+func (s *userService) LoginWithSMS(ctx context.Context, req dto.SMSLoginRequest) (*dto.UserLoginResponse, error) {
+	user, resp, err := s.smsLogin(ctx, req)
+	s.auditLogin(ctx, "sms", req.Phone, user, resp, err)
+	return resp, err
+}
+
+// smsLogin 使用短信验证码登录，手机号未注册且允许自动注册时创建新用户
+func (s *userService) smsLogin(ctx context.Context, req dto.SMSLoginRequest) (*models.User, *dto.UserLoginResponse, error) {
+	valid, message, err := s.codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, req.Phone, req.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !valid {
+		return nil, nil, errors.New(message)
 	}
 
-	accessToken, expiresIn, err := s.jwtUtil.GenerateToken(utils.UserInfo{
-		Id:          user.ID,
-		DisplayName: user.Name,
-		Email:       user.Email,
-		Avatar:      user.Avatar,
+	var user *models.User
+	err = withRegisterLock(ctx, "phone", req.Phone, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.FindByPhone(ctx, req.Phone)
+		if err != nil || user != nil {
+			return err
+		}
+		if !s.autoRegister {
+			return errors.New("该手机号未注册")
+		}
+		user = &models.User{
+			ID:     uuid.New().String(),
+			Phone:  &req.Phone,
+			Name:   "用户" + req.Phone[len(req.Phone)-4:],
+			Status: models.UserStatusActive,
+		}
+		return s.createUser(ctx, user)
+	})
+	if err != nil {
+		return user, nil, err
+	}
+
+	if user.IsPendingVerification() {
+		return user, nil, ErrEmailNotVerified
+	}
+	if !user.IsActive() {
+		return user, nil, errors.New("用户已被禁用")
+	}
+
+	resp, err := s.completeLogin(ctx, user, req.ClientInfo)
+	return user, resp, err
+}
+
+// LoginWithEmail 使用邮箱验证码登录
+func (s *userService) LoginWithEmail(ctx context.Context, req dto.EmailLoginRequest) (*dto.UserLoginResponse, error) {
+	user, resp, err := s.emailLogin(ctx, req)
+	s.auditLogin(ctx, "email", req.Email, user, resp, err)
+	return resp, err
+}
+
+// emailLogin 使用邮箱验证码登录，邮箱未注册且允许自动注册时创建新用户
+func (s *userService) emailLogin(ctx context.Context, req dto.EmailLoginRequest) (*models.User, *dto.UserLoginResponse, error) {
+	valid, message, err := s.codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, req.Email, req.Code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !valid {
+		return nil, nil, errors.New(message)
+	}
+
+	var user *models.User
+	err = withRegisterLock(ctx, "email", req.Email, func(ctx context.Context) error {
+		var err error
+		user, err = s.userRepo.FindByEmail(req.Email)
+		if err != nil || user != nil {
+			return err
+		}
+		if !s.autoRegister {
+			return errors.New("该邮箱未注册")
+		}
+		user = &models.User{
+			ID:     uuid.New().String(),
+			Email:  &req.Email,
+			Name:   strings.SplitN(req.Email, "@", 2)[0],
+			Status: models.UserStatusActive,
+		}
+		return s.createUser(ctx, user)
 	})
 	if err != nil {
-		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
+		return user, nil, err
+	}
+
+	// 邮箱验证码同样证明了对邮箱的控制权，可以完成注册时的邮箱验证
+	if user.IsPendingVerification() {
+		if err := s.activate(ctx, user); err != nil {
+			return user, nil, err
+		}
+	}
+	if !user.IsActive() {
+		return user, nil, errors.New("用户已被禁用")
+	}
+
+	// 验证码证明了对邮箱的控制权，同时解除因密码登录失败导致的锁定
+	s.throttle.Unlock(ctx, req.Email)
+
+	resp, err := s.completeLogin(ctx, user, req.ClientInfo)
+	return user, resp, err
+}
+
+// completeLogin 第一因素验证通过后完成登录
+// 启用两步验证时先签发临时令牌，验证通过后才签发正式令牌
+func (s *userService) completeLogin(ctx context.Context, user *models.User, client dto.ClientInfo) (*dto.UserLoginResponse, error) {
+	if user.TwoFactorEnabled {
+		mfaToken, expiresIn, err := s.jwtUtil.GenerateMFAToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.UserLoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   expiresIn,
+		}, nil
+	}
+
+	return s.issueLogin(ctx, user, client)
+}
+
+// LoginWith2FA 使用两步验证临时令牌和验证码（或恢复码）完成登录
+func (s *userService) LoginWith2FA(ctx context.Context, req dto.LoginMFARequest) (*dto.UserLoginResponse, error) {
+	user, resp, err := s.mfaLogin(ctx, req)
+	s.auditLogin(ctx, "2fa", "", user, resp, err)
+	return resp, err
+}
+
+// mfaLogin 校验两步验证临时令牌和验证码后签发正式令牌
+func (s *userService) mfaLogin(ctx context.Context, req dto.LoginMFARequest) (*models.User, *dto.UserLoginResponse, error) {
+	claims, err := s.jwtUtil.ValidateToken(ctx, req.MFAToken, utils.TokenTypeMFAPending)
+	if err != nil {
+		return nil, nil, errors.New("两步验证已过期，请重新登录")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserInfo.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil || !user.IsActive() || !user.TwoFactorEnabled {
+		return user, nil, errors.New("用户状态异常")
+	}
+
+	// 失败次数按用户统计，重新登录获取新的临时令牌不会重置
+	valid, err := s.mfaService.VerifyCode(ctx, user, req.Code)
+	if err != nil {
+		if errors.Is(err, ErrMFALocked) {
+			_ = s.jwtUtil.RevokeToken(ctx, claims)
+		}
+		return user, nil, err
+	}
+	if !valid {
+		return user, nil, errors.New("验证码错误")
+	}
+
+	// 临时令牌只能使用一次
+	if err := s.jwtUtil.RevokeToken(ctx, claims); err != nil {
+		return user, nil, fmt.Errorf("吊销令牌失败: %w", err)
+	}
+
+	resp, err := s.issueLogin(ctx, user, req.ClientInfo)
+	return user, resp, err
+}
+
+// auditLogin 记录登录审计事件，需要两步验证时记为第一因素通过
+func (s *userService) auditLogin(ctx context.Context, method string, subject string, user *models.User, resp *dto.UserLoginResponse, err error) {
+	event := auditEvent(models.AuditEventLogin, "", err)
+	event.Subject = subject
+	if user != nil {
+		event.ActorID = user.ID
+		event.TargetID = user.ID
+	}
+	switch {
+	case err != nil:
+		event.Detail = method + ": " + event.Detail
+	case resp != nil && resp.MFARequired:
+		event.Detail = method + ": 等待两步验证"
+	default:
+		event.Detail = method
+	}
+	RecordAudit(ctx, event)
+}
+
+// issueLogin 为已通过全部验证的用户签发令牌、记录登录会话并构造登录响应
+func (s *userService) issueLogin(ctx context.Context, user *models.User, client dto.ClientInfo) (*dto.UserLoginResponse, error) {
+	userInfo, err := s.buildUserInfo(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
-	refreshToken, err := s.jwtUtil.GenerateRefreshToken(user.ID)
+	tokens, err := s.jwtUtil.IssueTokenPair(ctx, userInfo, "")
 	if err != nil {
-		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
+		return nil, err
+	}
+	if err := s.sessions.record(ctx, user.ID, tokens, client); err != nil {
+		return nil, err
 	}
 
 	return &dto.UserLoginResponse{
-		User: dto.UserInfoResponse{
+		User: &dto.UserInfoResponse{
 			ID:        user.ID,
-			Email:     user.Email,
+			Email:     user.EmailAddress(),
+			Phone:     user.PhoneNumber(),
 			Name:      user.Name,
 			Avatar:    user.Avatar,
 			Status:    user.Status,
 			CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
 		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    expiresIn,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
 
-// RefreshToken 刷新访问令牌
-func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (*dto.RefreshTokenResponse, error) {
-	claims, err := s.jwtUtil.ParseToken(refreshToken)
+// RefreshToken 刷新访问令牌，同时轮换刷新令牌
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string, client dto.ClientInfo) (*dto.RefreshTokenResponse, error) {
+	claims, resp, err := s.refreshToken(ctx, refreshToken, client)
+
+	event := auditEvent(models.AuditEventTokenRefresh, "", err)
+	if claims != nil {
+		event.ActorID = claims.UserInfo.Id
+		event.TargetID = claims.UserInfo.Id
+	}
+	RecordAudit(ctx, event)
+
+	return resp, err
+}
+
+// refreshToken 校验并轮换刷新令牌，返回刷新令牌的声明用于审计
+func (s *userService) refreshToken(ctx context.Context, refreshToken string, client dto.ClientInfo) (*utils.Claims, *dto.RefreshTokenResponse, error) {
+	claims, err := s.jwtUtil.ValidateRefreshToken(ctx, refreshToken)
 	if err != nil {
-		return nil, errors.New("刷新令牌无效")
+		if errors.Is(err, utils.ErrRefreshTokenReused) {
+			return nil, nil, errors.New("刷新令牌已失效，请重新登录")
+		}
+		return nil, nil, errors.New("刷新令牌无效")
+	}
+
+	session, err := s.sessions.check(ctx, claims.Family)
+	if err != nil {
+		return claims, nil, err
 	}
 
 	user, err := s.userRepo.FindByID(ctx, claims.UserInfo.Id)
 	if err != nil {
-		return nil, err
+		return claims, nil, err
 	}
 	if user == nil {
-		return nil, errors.New("用户不存在")
+		return claims, nil, errors.New("用户不存在")
 	}
 
-	if user.Status != "active" {
-		return nil, errors.New("用户状态异常")
+	if !user.IsActive() {
+		return claims, nil, errors.New("用户状态异常")
 	}
 
-	accessToken, expiresIn, err := s.jwtUtil.GenerateToken(utils.UserInfo{
-		Id:          user.ID,
-		DisplayName: user.Name,
-		Email:       user.Email,
-		Avatar:      user.Avatar,
-	})
+	// 重新加载角色，使刷新后的访问令牌反映最新的角色分配
+	userInfo, err := s.buildUserInfo(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("生成访问令牌失败: %w", err)
+		return claims, nil, err
+	}
+
+	// 其他检查都通过后再消费旧的刷新令牌，避免临时故障导致令牌被消费而用户被迫重新登录
+	tokens, err := s.jwtUtil.RotateRefreshToken(ctx, claims, userInfo)
+	if err != nil {
+		if errors.Is(err, utils.ErrRefreshTokenReused) {
+			return claims, nil, errors.New("刷新令牌已失效，请重新登录")
+		}
+		return claims, nil, err
+	}
+	// 新令牌已签发，更新会话失败不影响本次刷新
+	if err := s.sessions.touch(ctx, session, user.ID, tokens, client); err != nil {
+		logger.Warn("更新会话失败", zap.String("user_id", user.ID), zap.String("family", claims.Family), zap.Error(err))
 	}
 
-	return &dto.RefreshTokenResponse{
-		AccessToken: accessToken,
-		ExpiresIn:   expiresIn,
+	return claims, &dto.RefreshTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
 
-// Logout 用户登出
+// Logout 用户登出，吊销当前访问令牌及其所属令牌族（使配套的刷新令牌失效）
 func (s *userService) Logout(ctx context.Context, accessToken string) error {
-	_, err := s.jwtUtil.ParseToken(accessToken)
+	claims, err := s.jwtUtil.ParseTokenOfType(accessToken, utils.TokenTypeAccess)
 	if err != nil {
 		return errors.New("访问令牌无效")
 	}
 
+	if err := s.jwtUtil.RevokeToken(ctx, claims); err != nil {
+		return fmt.Errorf("吊销令牌失败: %w", err)
+	}
+
+	if claims.Family != "" {
+		if err := s.jwtUtil.RevokeFamily(ctx, claims.Family); err != nil {
+			return fmt.Errorf("吊销令牌族失败: %w", err)
+		}
+		if err := s.sessions.revoke(ctx, claims.Family); err != nil {
+			return err
+		}
+	}
+
+	RecordAudit(ctx, auditEvent(models.AuditEventLogout, claims.UserInfo.Id, nil))
 	return nil
 }
 
+// LogoutAll 退出所有设备，吊销用户此前签发的所有令牌
+func (s *userService) LogoutAll(ctx context.Context, userID string) error {
+	err := s.revokeAllTokens(ctx, userID)
+	RecordAudit(ctx, auditEvent(models.AuditEventLogoutAll, userID, err))
+	return err
+}
+
+// revokeAllTokens 吊销用户所有已签发的令牌和会话
+func (s *userService) revokeAllTokens(ctx context.Context, userID string) error {
+	if err := s.jwtUtil.RevokeUserTokens(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("吊销令牌失败: %w", err)
+	}
+
+	return s.sessions.revokeAll(ctx, userID)
+}
+
 // GetUserByID 根据ID获取用户信息
 func (s *userService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
-	// 1. 先尝试从缓存获取
-	cacheKey := fmt.Sprintf("user:%s", id)
-	var user models.User
+	// 缓存未命中时从数据库加载，并发请求只查询一次；用户不存在时同样缓存，避免反复查询
+	return cache.GetOrLoad(ctx, fmt.Sprintf("user:%s", id), 1*time.Minute, func(ctx context.Context) (*models.User, error) {
+		return s.userRepo.FindByID(ctx, id)
+	})
+}
+
+// UpdateProfile 更新个人资料
+// 令牌中的昵称和头像在下次刷新令牌后更新
+func (s *userService) UpdateProfile(ctx context.Context, userID string, req dto.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if utf8.RuneCountInString(name) < 2 {
+			return nil, errors.New("昵称至少需要 2 个字符")
+		}
+		user.Name = name
+	}
 
-	// 尝试从缓存获取用户数据
-	err := cache.GetObject(ctx, cacheKey, &user)
-	if err == nil {
-		// 缓存命中，返回用户数据
-		return &user, nil
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"name": user.Name}); err != nil {
+		return nil, err
 	}
+	_ = cache.Forget(ctx, fmt.Sprintf("user:%s", user.ID))
 
-	fmt.Println("缓存未命中，从数据库获取用户: err=", err)
+	return user, nil
+}
 
-	// 2. 缓存未命中，从数据库获取
-	dbUser, err := s.userRepo.FindByID(ctx, id)
+// UpdateAvatar 上传头像，校验图片类型与大小，生成各尺寸的正方形缩略图并保存到文件存储
+func (s *userService) UpdateAvatar(ctx context.Context, userID string, r io.Reader) (*dto.AvatarResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
-	if dbUser == nil {
-		return nil, nil
+	if user == nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	maxSize := int64(s.avatarCfg.MaxSize) * 1024
+	if maxSize <= 0 {
+		maxSize = 2 << 20
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: 不能超过 %d KB", ErrAvatarTooLarge, maxSize/1024)
 	}
 
-	err = cache.PutObject(ctx, cacheKey, dbUser, 1*time.Minute)
+	img, err := utils.DecodeImage(data, s.avatarCfg.MaxDimension)
 	if err != nil {
-		fmt.Println("缓存用户数据失败:", err)
+		return nil, err
+	}
+
+	sizes := s.avatarCfg.Sizes
+	if len(sizes) == 0 {
+		sizes = []int{256, 128, 64}
+	}
+
+	// 文件路径固定，通过版本参数使浏览器和 CDN 缓存失效
+	version := strconv.FormatInt(time.Now().Unix(), 10)
+	resp := &dto.AvatarResponse{Thumbnails: make(map[int]string, len(sizes))}
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		if err := utils.EncodePNG(&buf, utils.Thumbnail(img, size)); err != nil {
+			return nil, fmt.Errorf("生成缩略图失败: %w", err)
+		}
+		path := fmt.Sprintf("avatars/%s/%d.png", user.ID, size)
+		if err := storage.Put(ctx, path, &buf, "image/png"); err != nil {
+			return nil, fmt.Errorf("保存头像失败: %w", err)
+		}
+		resp.Thumbnails[size] = storage.URL(path) + "?v=" + version
 	}
+	resp.Avatar = resp.Thumbnails[sizes[0]]
 
-	fmt.Println("缓存用户数据成功")
-	return dbUser, nil
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"avatar": resp.Avatar}); err != nil {
+		return nil, err
+	}
+	user.Avatar = resp.Avatar
+	_ = cache.Forget(ctx, fmt.Sprintf("user:%s", user.ID))
+
+	return resp, nil
 }
 
 // SendSMSCode 发送短信验证码
 func (s *userService) SendSMSCode(ctx context.Context, req dto.SendSMSCodeRequest) (*dto.SendSMSCodeResponse, error) {
-	// 生成6位随机验证码
-	code := fmt.Sprintf("%06d", rand.Intn(1000000))
-
-	// 模拟发送短信（实际项目中这里调用短信服务商API）
-	// 这里只是记录日志，表示"发送"了验证码
-	fmt.Printf("模拟发送短信验证码到 %s: %s\n", req.Phone, code)
-
-	// 将验证码存储到本地缓存中，有效期5分钟
-	cacheKey := fmt.Sprintf("sms_code:%s", req.Phone)
-	localCache := cache.Local()
-	if localCache == nil {
-		return nil, fmt.Errorf("本地缓存未初始化")
+	if err := s.codes.Allow(ctx, codeChannelSMS, req.Phone, req.IP); err != nil {
+		return nil, err
 	}
 
-	err := localCache.Put(ctx, cacheKey, code, 5*time.Minute)
+	code, expiresAt, err := s.codes.Issue(ctx, codeChannelSMS, codePurposeGeneral, req.Phone)
 	if err != nil {
-		return nil, fmt.Errorf("存储验证码失败: %w", err)
+		return nil, err
 	}
 
-	expiredAt := time.Now().Add(5 * time.Minute).Unix()
+	// 通过队列异步发送，失败时自动重试
+	err = task.DeliverSMS(ctx, notify.SMS{
+		Phone:   req.Phone,
+		Content: fmt.Sprintf("您的验证码为 %s，%d 分钟内有效，请勿泄露给他人。", code, int(s.codes.TTL().Minutes())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("发送短信失败: %w", err)
+	}
 
 	return &dto.SendSMSCodeResponse{
 		Message:   "验证码发送成功",
-		ExpiredAt: expiredAt,
+		ExpiredAt: expiresAt.Unix(),
 	}, nil
 }
 
 // VerifySMSCode 验证短信验证码
 func (s *userService) VerifySMSCode(ctx context.Context, req dto.VerifySMSCodeRequest) (*dto.VerifySMSCodeResponse, error) {
-	cacheKey := fmt.Sprintf("sms_code:%s", req.Phone)
+	valid, message, err := s.codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, req.Phone, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VerifySMSCodeResponse{
+		Valid:   valid,
+		Message: message,
+	}, nil
+}
+
+// SendEmailCode 发送邮箱验证码
+func (s *userService) SendEmailCode(ctx context.Context, req dto.SendEmailCodeRequest) (*dto.SendEmailCodeResponse, error) {
+	return s.sendEmailCode(ctx, codePurposeGeneral, req.Email, req.IP)
+}
+
+// VerifyEmailCode 验证邮箱验证码
+func (s *userService) VerifyEmailCode(ctx context.Context, req dto.VerifyEmailCodeRequest) (*dto.VerifyEmailCodeResponse, error) {
+	valid, message, err := s.codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, req.Email, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VerifyEmailCodeResponse{
+		Valid:   valid,
+		Message: message,
+	}, nil
+}
 
-	// 从本地缓存中获取验证码
-	localCache := cache.Local()
-	if localCache == nil {
-		return nil, fmt.Errorf("本地缓存未初始化")
+// ForgotPassword 发送重置密码验证码
+// 邮箱未注册时同样计入发送限制并返回成功，避免通过该接口探测账号是否存在
+func (s *userService) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) (*dto.SendEmailCodeResponse, error) {
+	if err := s.codes.Allow(ctx, codeChannelEmail, req.Email, req.IP); err != nil {
+		return nil, err
 	}
 
-	storedCode, err := localCache.Get(ctx, cacheKey)
+	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
-		return &dto.VerifySMSCodeResponse{
-			Valid:   false,
-			Message: "验证码已过期或不存在",
+		return nil, err
+	}
+	if user == nil || !user.IsActive() {
+		return &dto.SendEmailCodeResponse{
+			Message:   "验证码发送成功",
+			ExpiredAt: time.Now().Add(s.codes.TTL()).Unix(),
 		}, nil
 	}
 
-	// 验证验证码是否正确
-	if storedCode != req.Code {
-		return &dto.VerifySMSCodeResponse{
-			Valid:   false,
-			Message: "验证码错误",
-		}, nil
+	return s.deliverEmailCode(ctx, codePurposePasswordReset, req.Email)
+}
+
+// ResetPassword 使用邮箱验证码重置密码
+func (s *userService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
+
+	valid, message, err := s.codes.Verify(ctx, codeChannelEmail, codePurposePasswordReset, req.Email, req.Code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New(message)
 	}
 
-	// 验证成功后删除验证码（防止重复使用）
-	localCache.Forget(ctx, cacheKey)
+	user, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive() {
+		return errors.New("用户状态异常")
+	}
 
-	return &dto.VerifySMSCodeResponse{
-		Valid:   true,
-		Message: "验证码正确",
-	}, nil
+	if err := s.setPassword(ctx, user, req.NewPassword); err != nil {
+		return err
+	}
+
+	// 已通过邮箱验证身份，解除因登录失败导致的账号锁定
+	s.throttle.Unlock(ctx, user.EmailAddress())
+	return nil
 }
 
-// SendEmailCode 发送邮箱验证码
-func (s *userService) SendEmailCode(ctx context.Context, req dto.SendEmailCodeRequest) (*dto.SendEmailCodeResponse, error) {
-	// 生成6位随机验证码
-	code := fmt.Sprintf("%06d", rand.Intn(1000000))
+// ChangePassword 修改密码，需要验证原密码
+func (s *userService) ChangePassword(ctx context.Context, userID string, req dto.ChangePasswordRequest) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("用户不存在")
+	}
+
+	// 原密码校验与登录共用账号的失败计数，避免通过修改密码接口暴力猜测密码
+	account := throttleAccount(user)
+	if err := s.throttle.Check(ctx, account, ""); err != nil {
+		return err
+	}
+	if !s.verifyPassword(req.OldPassword, user.Password) {
+		s.throttle.Fail(ctx, account, "")
+		err := errors.New("原密码错误")
+		RecordAudit(ctx, auditEvent(models.AuditEventPasswordChange, user.ID, err))
+		return err
+	}
+	if req.OldPassword == req.NewPassword {
+		return errors.New("新密码不能与原密码相同")
+	}
+	if err := s.policy.Validate(req.NewPassword); err != nil {
+		return err
+	}
 
-	// 模拟发送邮件（实际项目中这里调用邮件服务商API）
-	// 这里只是记录日志，表示"发送"了验证码
-	fmt.Printf("模拟发送邮箱验证码到 %s: %s\n", req.Email, code)
+	return s.setPassword(ctx, user, req.NewPassword)
+}
 
-	// 将验证码存储到本地缓存中，有效期5分钟
-	cacheKey := fmt.Sprintf("email_code:%s", req.Email)
-	localCache := cache.Redis()
-	if localCache == nil {
-		return nil, fmt.Errorf("本地缓存未初始化")
+// throttleAccount 登录限制使用的账号标识，与邮箱登录共用计数；未绑定邮箱时按用户 ID 单独计数
+func throttleAccount(user *models.User) string {
+	if email := user.EmailAddress(); email != "" {
+		return email
 	}
+	return "id:" + user.ID
+}
 
-	err := localCache.Put(ctx, cacheKey, code, 5*time.Minute)
+// setPassword 保存新密码并记录审计事件
+func (s *userService) setPassword(ctx context.Context, user *models.User, password string) error {
+	err := s.savePassword(ctx, user, password)
+	RecordAudit(ctx, auditEvent(models.AuditEventPasswordChange, user.ID, err))
+	return err
+}
+
+// savePassword 保存新密码，吊销该用户所有已签发的令牌并发送密码变更通知
+func (s *userService) savePassword(ctx context.Context, user *models.User, password string) error {
+	hashedPassword, err := s.hashPassword(password)
 	if err != nil {
-		return nil, fmt.Errorf("存储验证码失败: %w", err)
+		return err
+	}
+	if err := s.userRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"password": hashedPassword}); err != nil {
+		return err
 	}
+	user.Password = hashedPassword
+	_ = cache.Forget(ctx, fmt.Sprintf("user:%s", user.ID))
 
-	expiredAt := time.Now().Add(5 * time.Minute).Unix()
+	// 所有设备需要使用新密码重新登录
+	if err := s.revokeAllTokens(ctx, user.ID); err != nil {
+		return err
+	}
 
-	return &dto.SendEmailCodeResponse{
-		Message:   "验证码发送成功",
-		ExpiredAt: expiredAt,
-	}, nil
-}
+	// 通知失败不影响密码修改结果
+	if _, err := task.EnqueuePasswordChangedTask(user.ID, user.EmailAddress()); err != nil {
+		logger.Warn("密码变更通知任务入队失败", zap.String("user_id", user.ID), zap.Error(err))
+	}
 
-// VerifyEmailCode 验证邮箱验证码
-func (s *userService) VerifyEmailCode(ctx context.Context, req dto.VerifyEmailCodeRequest) (*dto.VerifyEmailCodeResponse, error) {
-	cacheKey := fmt.Sprintf("email_code:%s", req.Email)
+	return nil
+}
 
-	// 从本地缓存中获取验证码
-	localCache := cache.Redis()
-	if localCache == nil {
-		return nil, fmt.Errorf("本地缓存未初始化")
+// sendEmailCode 检查发送限制后生成指定用途的邮箱验证码并发送
+func (s *userService) sendEmailCode(ctx context.Context, purpose string, email string, ip string) (*dto.SendEmailCodeResponse, error) {
+	if err := s.codes.Allow(ctx, codeChannelEmail, email, ip); err != nil {
+		return nil, err
 	}
+	return s.deliverEmailCode(ctx, purpose, email)
+}
 
-	storedCode, err := localCache.Get(ctx, cacheKey)
+// deliverEmailCode 生成指定用途的邮箱验证码并发送，调用方需已检查发送限制
+func (s *userService) deliverEmailCode(ctx context.Context, purpose string, email string) (*dto.SendEmailCodeResponse, error) {
+	code, expiresAt, err := s.codes.Issue(ctx, codeChannelEmail, purpose, email)
 	if err != nil {
-		return &dto.VerifyEmailCodeResponse{
-			Valid:   false,
-			Message: "验证码已过期或不存在",
-		}, nil
+		return nil, err
 	}
 
-	// 验证验证码是否正确
-	if storedCode != req.Code {
-		return &dto.VerifyEmailCodeResponse{
-			Valid:   false,
-			Message: "验证码错误",
-		}, nil
+	subject := "邮箱验证码"
+	if purpose == codePurposePasswordReset {
+		subject = "重置密码验证码"
 	}
 
-	// 验证成功后删除验证码（防止重复使用）
-	localCache.Forget(ctx, cacheKey)
+	// 通过队列异步发送，失败时自动重试
+	err = task.DeliverEmail(ctx, notify.Email{
+		To:      email,
+		Subject: subject,
+		Body:    fmt.Sprintf("您的验证码为 %s，%d 分钟内有效，请勿泄露给他人。", code, int(s.codes.TTL().Minutes())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("发送邮件失败: %w", err)
+	}
 
-	return &dto.VerifyEmailCodeResponse{
-		Valid:   true,
-		Message: "验证码正确",
+	return &dto.SendEmailCodeResponse{
+		Message:   "验证码发送成功",
+		ExpiredAt: expiresAt.Unix(),
+	}, nil
+}
+
+// buildUserInfo 构造写入令牌的用户信息，包含用户当前的角色
+func (s *userService) buildUserInfo(ctx context.Context, user *models.User) (utils.UserInfo, error) {
+	roles, err := s.roleRepo.GetUserRoleNames(ctx, user.ID)
+	if err != nil {
+		return utils.UserInfo{}, err
+	}
+
+	return utils.UserInfo{
+		Id:          user.ID,
+		DisplayName: user.Name,
+		Email:       user.EmailAddress(),
+		Phone:       user.PhoneNumber(),
+		Avatar:      user.Avatar,
+		Roles:       roles,
 	}, nil
 }
 
 // hashPassword 密码加密
-func (s *userService) hashPassword(password string) string {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(hashedPassword)
+func (s *userService) hashPassword(password string) (string, error) {
+	return s.hasher.Hash(password)
 }
 
 // verifyPassword 验证密码
 func (s *userService) verifyPassword(password, hashedPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	ok, err := s.hasher.Verify(password, hashedPassword)
+	return err == nil && ok
+}
+
+// rehashIfNeeded 登录成功后，旧算法或弱参数的哈希使用当前配置重新计算，失败不影响登录
+func (s *userService) rehashIfNeeded(ctx context.Context, user *models.User, password string) {
+	if !s.hasher.NeedsRehash(user.Password) {
+		return
+	}
+	hashedPassword, err := s.hashPassword(password)
+	if err != nil {
+		logger.Warn("重新计算密码哈希失败", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+	// 只更新密码列，并以读取时的哈希为条件，避免覆盖并发修改的密码或其他字段
+	updated, err := s.userRepo.UpdatePassword(ctx, user.ID, user.Password, hashedPassword)
+	if err != nil {
+		logger.Warn("更新密码哈希失败", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+	if updated {
+		user.Password = hashedPassword
+	}
 }
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"mygoframe/internal/models"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrCodeCooldown 验证码发送过于频繁
+	ErrCodeCooldown = errors.New("验证码发送过于频繁，请稍后再试")
+	// ErrCodeQuotaExceeded 验证码发送次数超过每日上限
+	ErrCodeQuotaExceeded = errors.New("今日验证码发送次数已达上限")
+)
+
+// 验证码发送渠道
+const (
+	codeChannelSMS   = "sms"
+	codeChannelEmail = "email"
+)
+
+// 验证码用途，不同用途的验证码互不通用
+const (
+	codePurposeGeneral       = ""
+	codePurposePasswordReset = "password_reset"
+)
+
+// verificationCode 缓存中保存的验证码，只保存加盐摘要
+type verificationCode struct {
+	Salt      string    `json:"salt"`
+	Hash      string    `json:"hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// codeManager 短信与邮箱验证码的生成、校验与防滥用限制
+type codeManager struct {
+	ttl          time.Duration
+	cooldown     time.Duration
+	dailyLimit   int
+	ipDailyLimit int
+	maxAttempts  int
+}
+
+// newCodeManager 根据配置创建验证码管理器，未配置的项使用默认值
+func newCodeManager(cfg config.VerifyCode) *codeManager {
+	m := &codeManager{
+		ttl:          time.Duration(cfg.TTL) * time.Second,
+		cooldown:     time.Duration(cfg.Cooldown) * time.Second,
+		dailyLimit:   cfg.DailyLimit,
+		ipDailyLimit: cfg.IPDailyLimit,
+		maxAttempts:  cfg.MaxAttempts,
+	}
+	if m.ttl <= 0 {
+		m.ttl = 5 * time.Minute
+	}
+	if m.maxAttempts <= 0 {
+		m.maxAttempts = 5
+	}
+	return m
+}
+
+// Allow 检查发送冷却时间与每日配额，允许发送时记录本次发送；被限制的发送请求记入审计日志
+func (m *codeManager) Allow(ctx context.Context, channel string, target string, ip string) error {
+	err := m.allow(ctx, channel, target, ip)
+	if err != nil {
+		event := auditEvent(models.AuditEventCodeSent, "", err)
+		event.Subject = target
+		event.Detail = channel + ": " + event.Detail
+		RecordAudit(ctx, event)
+	}
+	return err
+}
+
+// allow 检查发送冷却时间与每日配额
+// 冷却键通过 Add 原子地占用，配额通过 Increment 原子地累加，并发请求不会绕过限制
+// 缓存不可用时无法判断是否超限，拒绝发送，避免限制失效后被用于短信轰炸
+func (m *codeManager) allow(ctx context.Context, channel string, target string, ip string) error {
+	target = strings.ToLower(target)
+	if m.cooldown > 0 {
+		cooldownKey := fmt.Sprintf("verify_code:cooldown:%s:%s", channel, target)
+		added, err := cache.Add(ctx, cooldownKey, "1", m.cooldown)
+		if err != nil {
+			logger.Error("检查验证码发送冷却失败", zap.String("channel", channel), zap.String("target", target), zap.Error(err))
+			return fmt.Errorf("检查验证码发送限制失败: %w", err)
+		}
+		if !added {
+			return ErrCodeCooldown
+		}
+	}
+
+	day := time.Now().Format("20060102")
+	if m.dailyLimit > 0 {
+		targetKey := fmt.Sprintf("verify_code:quota:%s:%s:%s", channel, target, day)
+		n, err := cache.Increment(ctx, targetKey, 1, 24*time.Hour)
+		if err != nil {
+			logger.Error("记录验证码发送次数失败", zap.String("channel", channel), zap.String("target", target), zap.Error(err))
+			return fmt.Errorf("检查验证码发送限制失败: %w", err)
+		}
+		if n > int64(m.dailyLimit) {
+			logger.Warn("验证码发送次数超过每日上限", zap.String("channel", channel), zap.String("target", target))
+			return ErrCodeQuotaExceeded
+		}
+	}
+	if ip != "" && m.ipDailyLimit > 0 {
+		ipKey := fmt.Sprintf("verify_code:quota:ip:%s:%s", ip, day)
+		n, err := cache.Increment(ctx, ipKey, 1, 24*time.Hour)
+		if err != nil {
+			logger.Error("记录验证码发送次数失败", zap.String("channel", channel), zap.String("ip", ip), zap.Error(err))
+			return fmt.Errorf("检查验证码发送限制失败: %w", err)
+		}
+		if n > int64(m.ipDailyLimit) {
+			logger.Warn("验证码发送次数超过 IP 每日上限", zap.String("channel", channel), zap.String("ip", ip))
+			return ErrCodeQuotaExceeded
+		}
+	}
+
+	return nil
+}
+
+// Issue 生成新的验证码并保存摘要，覆盖此前未使用的验证码
+func (m *codeManager) Issue(ctx context.Context, channel string, purpose string, target string) (string, time.Time, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("生成验证码失败: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", time.Time{}, fmt.Errorf("生成验证码失败: %w", err)
+	}
+
+	entry := verificationCode{
+		Salt:      hex.EncodeToString(salt),
+		ExpiresAt: time.Now().Add(m.ttl),
+	}
+	entry.Hash = hashVerificationCode(entry.Salt, code)
+
+	key := codeKey(channel, purpose, target)
+	if err := cache.PutObject(ctx, key, entry, m.ttl); err != nil {
+		return "", time.Time{}, fmt.Errorf("存储验证码失败: %w", err)
+	}
+	_ = cache.Forget(ctx, attemptsKey(key))
+
+	event := auditEvent(models.AuditEventCodeSent, "", nil)
+	event.Subject = target
+	event.Detail = codeAuditDetail(channel, purpose, "")
+	RecordAudit(ctx, event)
+
+	return code, entry.ExpiresAt, nil
+}
+
+// Verify 校验验证码并记录审计事件
+func (m *codeManager) Verify(ctx context.Context, channel string, purpose string, target string, code string) (bool, string, error) {
+	valid, message, err := m.verify(ctx, channel, purpose, target, code)
+	if err == nil {
+		event := auditEvent(models.AuditEventCodeVerified, "", nil)
+		if !valid {
+			event.Outcome = models.AuditOutcomeFailure
+		}
+		event.Subject = target
+		event.Detail = codeAuditDetail(channel, purpose, message)
+		RecordAudit(ctx, event)
+	}
+	return valid, message, err
+}
+
+// verify 校验验证码，成功后删除验证码；校验次数达到上限后验证码作废
+// 每次校验先原子地累加次数再比较，超过上限的请求不再比较，并发的猜测请求不能超过上限；
+// 校验通过后通过 Pull 原子地取出验证码，同一个验证码只能成功使用一次
+func (m *codeManager) verify(ctx context.Context, channel string, purpose string, target string, code string) (bool, string, error) {
+	key := codeKey(channel, purpose, target)
+
+	var entry verificationCode
+	if err := cache.GetObject(ctx, key, &entry); err != nil || time.Now().After(entry.ExpiresAt) {
+		return false, "验证码已过期或不存在", nil
+	}
+
+	attempts, err := cache.Increment(ctx, attemptsKey(key), 1, time.Until(entry.ExpiresAt))
+	if err != nil {
+		return false, "", fmt.Errorf("更新验证码失败: %w", err)
+	}
+	if attempts > int64(m.maxAttempts) {
+		_ = cache.Forget(ctx, key)
+		return false, "验证码错误次数过多，请重新获取", nil
+	}
+
+	if !entry.matches(code) {
+		if attempts >= int64(m.maxAttempts) {
+			// 计数保留到验证码过期，已读取验证码的并发请求同样超过上限
+			_ = cache.Forget(ctx, key)
+			logger.Warn("验证码错误次数过多，已作废", zap.String("channel", channel), zap.String("target", target))
+			return false, "验证码错误次数过多，请重新获取", nil
+		}
+		return false, "验证码错误", nil
+	}
+
+	// 取出并删除验证码（防止重复使用），并发校验时只有一个请求能取到
+	data, err := cache.Pull(ctx, key)
+	if err != nil {
+		return false, "验证码已过期或不存在", nil
+	}
+	// 读取后验证码可能已被重新发送，以取出的验证码为准，不匹配时放回新的验证码
+	var pulled verificationCode
+	if err := cache.DeserializeObject(data, &pulled); err != nil {
+		return false, "验证码已过期或不存在", nil
+	}
+	if !pulled.matches(code) {
+		if _, err := cache.Add(ctx, key, data, time.Until(pulled.ExpiresAt)); err != nil {
+			logger.Warn("放回验证码失败", zap.String("channel", channel), zap.String("target", target), zap.Error(err))
+		}
+		return false, "验证码已过期或不存在", nil
+	}
+	_ = cache.Forget(ctx, attemptsKey(key))
+
+	return true, "验证码正确", nil
+}
+
+// matches 比较验证码摘要
+func (c verificationCode) matches(code string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashVerificationCode(c.Salt, code)), []byte(c.Hash)) == 1
+}
+
+// TTL 验证码有效期
+func (m *codeManager) TTL() time.Duration {
+	return m.ttl
+}
+
+// codeAuditDetail 验证码审计事件的说明，包含渠道、用途和校验结果
+func codeAuditDetail(channel string, purpose string, message string) string {
+	detail := channel
+	if purpose != codePurposeGeneral {
+		detail += "/" + purpose
+	}
+	if message != "" {
+		detail += ": " + message
+	}
+	return detail
+}
+
+// codeKey 验证码缓存键
+func codeKey(channel string, purpose string, target string) string {
+	target = strings.ToLower(target)
+	if purpose == codePurposeGeneral {
+		return fmt.Sprintf("verify_code:%s:%s", channel, target)
+	}
+	return fmt.Sprintf("verify_code:%s:%s:%s", channel, purpose, target)
+}
+
+// attemptsKey 验证码错误次数计数器的缓存键
+func attemptsKey(codeKey string) string {
+	return codeKey + ":attempts"
+}
+
+// hashVerificationCode 计算验证码的加盐摘要，缓存中不保存验证码明文
+func hashVerificationCode(salt string, code string) string {
+	sum := sha256.Sum256([]byte(salt + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
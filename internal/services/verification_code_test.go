@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/cache"
+)
+
+func TestCodeManagerCooldown(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, cooldown: time.Minute, maxAttempts: 5}
+
+	if err := codes.Allow(ctx, codeChannelSMS, "13800000000", ""); err != nil {
+		t.Fatalf("首次 Allow = %v, 期望 nil", err)
+	}
+	if err := codes.Allow(ctx, codeChannelSMS, "13800000000", ""); !errors.Is(err, ErrCodeCooldown) {
+		t.Errorf("冷却期内 Allow = %v, 期望 ErrCodeCooldown", err)
+	}
+	// 冷却按渠道和目标分别计算
+	if err := codes.Allow(ctx, codeChannelEmail, "13800000000", ""); err != nil {
+		t.Errorf("其他渠道 Allow = %v, 期望 nil", err)
+	}
+	if err := codes.Allow(ctx, codeChannelSMS, "13800000001", ""); err != nil {
+		t.Errorf("其他目标 Allow = %v, 期望 nil", err)
+	}
+
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if codes.Allow(ctx, codeChannelEmail, "race@example.com", "") == nil {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 1 {
+		t.Errorf("并发发送成功次数 = %d, 期望 1", allowed)
+	}
+}
+
+func TestCodeManagerDailyLimit(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, dailyLimit: 2, ipDailyLimit: 3, maxAttempts: 5}
+
+	for i := 0; i < 2; i++ {
+		if err := codes.Allow(ctx, codeChannelEmail, "a@example.com", "10.0.0.1"); err != nil {
+			t.Fatalf("第 %d 次 Allow = %v, 期望 nil", i+1, err)
+		}
+	}
+	// 目标不区分大小写
+	if err := codes.Allow(ctx, codeChannelEmail, "A@example.com", "10.0.0.1"); !errors.Is(err, ErrCodeQuotaExceeded) {
+		t.Errorf("超过目标每日上限 Allow = %v, 期望 ErrCodeQuotaExceeded", err)
+	}
+	// 因目标超限被拒绝的请求不计入 IP 配额
+	if err := codes.Allow(ctx, codeChannelEmail, "b@example.com", "10.0.0.1"); err != nil {
+		t.Fatalf("IP 未达到上限时 Allow = %v, 期望 nil", err)
+	}
+	if err := codes.Allow(ctx, codeChannelEmail, "c@example.com", "10.0.0.1"); !errors.Is(err, ErrCodeQuotaExceeded) {
+		t.Errorf("超过 IP 每日上限 Allow = %v, 期望 ErrCodeQuotaExceeded", err)
+	}
+	if err := codes.Allow(ctx, codeChannelEmail, "c@example.com", "10.0.0.2"); err != nil {
+		t.Errorf("其他 IP Allow = %v, 期望 nil", err)
+	}
+}
+
+func TestCodeManagerAllowFailsClosed(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, dailyLimit: 5, maxAttempts: 5}
+
+	// 配额计数器无法累加时拒绝发送，而不是跳过限制
+	quotaKey := fmt.Sprintf("verify_code:quota:%s:%s:%s", codeChannelSMS, "13800000000", time.Now().Format("20060102"))
+	if err := cache.Put(ctx, quotaKey, "invalid", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	err := codes.Allow(ctx, codeChannelSMS, "13800000000", "")
+	if err == nil || errors.Is(err, ErrCodeQuotaExceeded) {
+		t.Errorf("缓存出错时 Allow = %v, 期望返回缓存错误", err)
+	}
+}
+
+func TestCodeManagerVerifySingleUse(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, maxAttempts: 5}
+
+	code, _, err := codes.Issue(ctx, codeChannelSMS, codePurposeGeneral, "13800000000")
+	if err != nil {
+		t.Fatalf("Issue失败: %v", err)
+	}
+	// 不同用途的验证码互不通用
+	if valid, _, _ := codes.Verify(ctx, codeChannelSMS, codePurposePasswordReset, "13800000000", code); valid {
+		t.Error("其他用途的验证码不应通过校验")
+	}
+	if valid, msg, err := codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, "13800000000", code); err != nil || !valid {
+		t.Fatalf("Verify = %v, %q, %v, 期望通过", valid, msg, err)
+	}
+	if valid, _, _ := codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, "13800000000", code); valid {
+		t.Error("验证码使用后不应再次通过校验")
+	}
+
+	// 并发提交同一个验证码时只有一个请求成功
+	code, _, _ = codes.Issue(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com")
+	var succeeded int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if valid, _, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", code); valid {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Errorf("并发校验成功次数 = %d, 期望 1", succeeded)
+	}
+}
+
+func TestCodeManagerMaxAttempts(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, maxAttempts: 3}
+
+	code, _, _ := codes.Issue(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com")
+	wrong := wrongCode(code)
+
+	for i := 0; i < 2; i++ {
+		if valid, msg, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", wrong); valid || msg != "验证码错误" {
+			t.Fatalf("第 %d 次错误校验 = %v, %q", i+1, valid, msg)
+		}
+	}
+	if _, msg, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", wrong); msg != "验证码错误次数过多，请重新获取" {
+		t.Errorf("达到错误上限时 = %q", msg)
+	}
+	if valid, _, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", code); valid {
+		t.Error("错误次数达到上限后验证码应作废")
+	}
+
+	// 重新发送后错误次数重新计算
+	for round := 0; round < 2; round++ {
+		code, _, _ = codes.Issue(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com")
+		for i := 0; i < 2; i++ {
+			_, _, _ = codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", wrongCode(code))
+		}
+	}
+	if valid, _, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", code); !valid {
+		t.Error("重新发送后应按新的错误次数计算")
+	}
+}
+
+func TestCodeManagerCountsAttemptBeforeCompare(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: time.Minute, maxAttempts: 3}
+
+	code, _, _ := codes.Issue(ctx, codeChannelSMS, codePurposeGeneral, "13800000000")
+	// 模拟并发请求已用完次数、但验证码尚未被作废：超过上限的请求即使验证码正确也不再比较
+	key := codeKey(codeChannelSMS, codePurposeGeneral, "13800000000")
+	if err := cache.Put(ctx, attemptsKey(key), "3", time.Minute); err != nil {
+		t.Fatalf("写入缓存失败: %v", err)
+	}
+	if valid, msg, _ := codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, "13800000000", code); valid || msg != "验证码错误次数过多，请重新获取" {
+		t.Errorf("超过上限后 Verify = %v, %q, 期望拒绝", valid, msg)
+	}
+
+	// 并发猜测时最多比较 maxAttempts 次
+	code, _, _ = codes.Issue(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _ = codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", wrongCode(code))
+		}()
+	}
+	wg.Wait()
+	if valid, _, _ := codes.Verify(ctx, codeChannelEmail, codePurposeGeneral, "a@example.com", code); valid {
+		t.Error("并发猜测用完次数后验证码应作废")
+	}
+}
+
+func TestCodeManagerExpired(t *testing.T) {
+	newTestCache(t)
+	ctx := context.Background()
+	codes := &codeManager{ttl: 100 * time.Millisecond, maxAttempts: 5}
+
+	code, _, _ := codes.Issue(ctx, codeChannelSMS, codePurposeGeneral, "13800000000")
+	time.Sleep(150 * time.Millisecond)
+	if valid, msg, _ := codes.Verify(ctx, codeChannelSMS, codePurposeGeneral, "13800000000", code); valid || msg != "验证码已过期或不存在" {
+		t.Errorf("过期后 Verify = %v, %q", valid, msg)
+	}
+}
+
+// wrongCode 返回与 code 不同的六位验证码
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "000001"
+	}
+	return "000000"
+}
@@ -0,0 +1,53 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mygoframe/internal/models"
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/queue"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+func NewAuditEventTask(event models.AuditEvent) (*asynq.Task, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeAuditEvent, payload), nil
+}
+
+// NewAuditEventHandler 将队列中的审计事件写入数据库
+func NewAuditEventHandler(db *gorm.DB) func(context.Context, *asynq.Task) error {
+	auditRepo := repositories.NewAuditEventRepository(db)
+	return func(ctx context.Context, t *asynq.Task) error {
+		var event models.AuditEvent
+		if err := json.Unmarshal(t.Payload(), &event); err != nil {
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		return auditRepo.Create(ctx, &event)
+	}
+}
+
+// EnqueueAuditEventTask 审计事件入队，由队列异步写入数据库
+func EnqueueAuditEventTask(event models.AuditEvent) (*asynq.TaskInfo, error) {
+	if queue.Client == nil {
+		return nil, fmt.Errorf("队列服务未启用")
+	}
+
+	task, err := NewAuditEventTask(event)
+	if err != nil {
+		return nil, fmt.Errorf("创建审计事件任务失败: %w", err)
+	}
+
+	info, err := queue.Client.Enqueue(task, asynq.MaxRetry(deliveryMaxRetry), asynq.Queue("low"))
+	if err != nil {
+		return nil, fmt.Errorf("任务入队失败: %w", err)
+	}
+
+	return info, nil
+}
@@ -7,13 +7,24 @@ import (
 	"log"
 	"time"
 
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/notify"
 	"mygoframe/pkg/queue"
 
 	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
 )
 
-func NewWelcomeEmailTask(userID int) (*asynq.Task, error) {
-	payload, err := json.Marshal(map[string]interface{}{"user_id": userID})
+// WelcomeEmailPayload 欢迎邮件任务参数，VerifyURL 不为空时邮件中附带邮箱验证链接
+type WelcomeEmailPayload struct {
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Name      string `json:"name"`
+	VerifyURL string `json:"verify_url,omitempty"`
+}
+
+func NewWelcomeEmailTask(p WelcomeEmailPayload) (*asynq.Task, error) {
+	payload, err := json.Marshal(p)
 	if err != nil {
 		return nil, err
 	}
@@ -21,17 +32,23 @@ func NewWelcomeEmailTask(userID int) (*asynq.Task, error) {
 }
 
 func HandleWelcomeEmailTask(ctx context.Context, t *asynq.Task) error {
-	var p map[string]interface{}
+	var p WelcomeEmailPayload
 	if err := json.Unmarshal(t.Payload(), &p); err != nil {
 		return err
 	}
-	userID := p["user_id"]
-	log.Printf("Sending a welcome email to user %v", userID)
-	return nil
+	log.Printf("Sending a welcome email to user %v", p.UserID)
+	if p.Email == "" {
+		return nil
+	}
+	return notify.SendEmail(ctx, welcomeEmail(p))
 }
 
-func EnqueueWelcomeEmailTask(userID int, queueName string) (*asynq.TaskInfo, error) {
-	task, err := NewWelcomeEmailTask(userID)
+func EnqueueWelcomeEmailTask(p WelcomeEmailPayload, queueName string) (*asynq.TaskInfo, error) {
+	if queue.Client == nil {
+		return nil, fmt.Errorf("队列服务未启用")
+	}
+
+	task, err := NewWelcomeEmailTask(p)
 	if err != nil {
 		return nil, fmt.Errorf("创建欢迎邮件任务失败: %w", err)
 	}
@@ -44,6 +61,26 @@ func EnqueueWelcomeEmailTask(userID int, queueName string) (*asynq.TaskInfo, err
 	return info, nil
 }
 
+// DeliverWelcomeEmail 通过队列发送欢迎邮件，队列服务未启用时直接发送
+func DeliverWelcomeEmail(ctx context.Context, p WelcomeEmailPayload) error {
+	if queue.Client == nil {
+		logger.Warn("队列服务未启用，直接发送欢迎邮件", zap.String("user_id", p.UserID))
+		return notify.SendEmail(ctx, welcomeEmail(p))
+	}
+
+	_, err := EnqueueWelcomeEmailTask(p, "critical")
+	return err
+}
+
+// welcomeEmail 构造欢迎邮件内容
+func welcomeEmail(p WelcomeEmailPayload) notify.Email {
+	body := fmt.Sprintf("%s，您好：\n\n欢迎注册 MyGoFrame。", p.Name)
+	if p.VerifyURL != "" {
+		body += fmt.Sprintf("请点击以下链接验证您的邮箱，验证后即可登录：\n\n%s\n\n如非本人操作，请忽略本邮件。", p.VerifyURL)
+	}
+	return notify.Email{To: p.Email, Subject: "欢迎注册", Body: body}
+}
+
 type SendLaterEmailPayload struct {
 	UserID string    `json:"user_id"`
 	SendAt time.Time `json:"send_at"`
@@ -79,3 +116,57 @@ func EnqueueSendLaterEmailTask(userID string, delay time.Duration) (*asynq.TaskI
 
 	return info, nil
 }
+
+// PasswordChangedPayload 密码变更通知任务参数
+type PasswordChangedPayload struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func NewPasswordChangedTask(userID, email string, changedAt time.Time) (*asynq.Task, error) {
+	payload, err := json.Marshal(PasswordChangedPayload{UserID: userID, Email: email, ChangedAt: changedAt})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypePasswordChanged, payload), nil
+}
+
+func HandlePasswordChangedTask(ctx context.Context, t *asynq.Task) error {
+	var p PasswordChangedPayload
+	if err := json.Unmarshal(t.Payload(), &p); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	if p.Email == "" {
+		// 通过手机号注册且未绑定邮箱的用户无法接收邮件通知
+		log.Printf("Skipping password changed notice for user %s without email", p.UserID)
+		return nil
+	}
+
+	log.Printf("Sending password changed notice to %s (user %s), changed at %s", p.Email, p.UserID, p.ChangedAt.Format(time.RFC3339))
+	return notify.SendEmail(ctx, notify.Email{
+		To:      p.Email,
+		Subject: "密码已修改",
+		Body: fmt.Sprintf("您的账号密码已于 %s 修改，所有设备均已退出登录。如非本人操作，请立即通过忘记密码重置密码。",
+			p.ChangedAt.Format("2006-01-02 15:04:05")),
+	})
+}
+
+// EnqueuePasswordChangedTask 通知用户密码已变更
+func EnqueuePasswordChangedTask(userID, email string) (*asynq.TaskInfo, error) {
+	if queue.Client == nil {
+		return nil, fmt.Errorf("队列服务未启用")
+	}
+
+	task, err := NewPasswordChangedTask(userID, email, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("创建密码变更通知任务失败: %w", err)
+	}
+
+	info, err := queue.Client.Enqueue(task, asynq.MaxRetry(3), asynq.Queue("critical"))
+	if err != nil {
+		return nil, fmt.Errorf("任务入队失败: %w", err)
+	}
+
+	return info, nil
+}
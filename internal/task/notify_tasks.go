@@ -0,0 +1,89 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/notify"
+	"mygoframe/pkg/queue"
+
+	"github.com/hibiken/asynq"
+	"go.uber.org/zap"
+)
+
+// 消息发送任务的重试次数与超时时间，发送失败时由 asynq 按退避策略重试
+const (
+	deliveryMaxRetry = 5
+	deliveryTimeout  = 30 * time.Second
+)
+
+func NewSendEmailTask(msg notify.Email) (*asynq.Task, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSendEmail, payload), nil
+}
+
+func HandleSendEmailTask(ctx context.Context, t *asynq.Task) error {
+	var msg notify.Email
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	return notify.SendEmail(ctx, msg)
+}
+
+func NewSendSMSTask(msg notify.SMS) (*asynq.Task, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSendSMS, payload), nil
+}
+
+func HandleSendSMSTask(ctx context.Context, t *asynq.Task) error {
+	var msg notify.SMS
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	return notify.SendSMS(ctx, msg)
+}
+
+// DeliverEmail 通过队列异步发送邮件，队列服务未启用时直接发送
+func DeliverEmail(ctx context.Context, msg notify.Email) error {
+	if queue.Client == nil {
+		logger.Warn("队列服务未启用，直接发送邮件", zap.String("to", msg.To))
+		return notify.SendEmail(ctx, msg)
+	}
+
+	task, err := NewSendEmailTask(msg)
+	if err != nil {
+		return fmt.Errorf("创建邮件发送任务失败: %w", err)
+	}
+	if _, err := queue.Client.EnqueueContext(ctx, task, asynq.MaxRetry(deliveryMaxRetry), asynq.Timeout(deliveryTimeout), asynq.Queue("critical")); err != nil {
+		return fmt.Errorf("任务入队失败: %w", err)
+	}
+
+	return nil
+}
+
+// DeliverSMS 通过队列异步发送短信，队列服务未启用时直接发送
+func DeliverSMS(ctx context.Context, msg notify.SMS) error {
+	if queue.Client == nil {
+		logger.Warn("队列服务未启用，直接发送短信", zap.String("phone", msg.Phone))
+		return notify.SendSMS(ctx, msg)
+	}
+
+	task, err := NewSendSMSTask(msg)
+	if err != nil {
+		return fmt.Errorf("创建短信发送任务失败: %w", err)
+	}
+	if _, err := queue.Client.EnqueueContext(ctx, task, asynq.MaxRetry(deliveryMaxRetry), asynq.Timeout(deliveryTimeout), asynq.Queue("critical")); err != nil {
+		return fmt.Errorf("任务入队失败: %w", err)
+	}
+
+	return nil
+}
@@ -1,12 +1,26 @@
 package task
 
 import (
+	"time"
+
+	"mygoframe/pkg/config"
 	"mygoframe/pkg/queue"
+
+	"gorm.io/gorm"
 )
 
-func Setup() {
+func Setup(cfg *config.Config, db *gorm.DB) {
 	queue.RegisterHandler(TypeWelcomeEmail, HandleWelcomeEmailTask)
 	queue.RegisterHandler(TypeSendLaterEmail, HandleSendLaterEmailTask)
+	queue.RegisterHandler(TypePasswordChanged, HandlePasswordChangedTask)
+	queue.RegisterHandler(TypeSendEmail, HandleSendEmailTask)
+	queue.RegisterHandler(TypeSendSMS, HandleSendSMSTask)
+	queue.RegisterHandler(TypeAuditEvent, NewAuditEventHandler(db))
 
 	queue.RegisterCronJob("@every 1m", NewHelloWorldTask())
+
+	if retention := cfg.Register.UnverifiedRetention; retention > 0 {
+		queue.RegisterHandler(CronPurgeUnverifiedUsers, NewPurgeUnverifiedUsersHandler(db, time.Duration(retention)*time.Hour))
+		queue.RegisterCronJob("@every 1h", NewPurgeUnverifiedUsersTask())
+	}
 }
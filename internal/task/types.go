@@ -1,7 +1,12 @@
 package task
 
 const (
-	TypeWelcomeEmail   = "queue:welcome"
-	TypeSendLaterEmail = "queue:send_later"
-	CronHelloWorld     = "cron:hello_world"
+	TypeWelcomeEmail         = "queue:welcome"
+	TypeSendLaterEmail       = "queue:send_later"
+	TypePasswordChanged      = "queue:password_changed"
+	TypeSendEmail            = "queue:send_email"
+	TypeSendSMS              = "queue:send_sms"
+	TypeAuditEvent           = "queue:audit_event"
+	CronHelloWorld           = "cron:hello_world"
+	CronPurgeUnverifiedUsers = "cron:purge_unverified_users"
 )
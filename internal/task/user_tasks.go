@@ -0,0 +1,45 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"mygoframe/internal/repositories"
+	"mygoframe/pkg/cache"
+
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// purgeLockTTL 清理任务的锁有效期，执行期间自动续期
+const purgeLockTTL = 5 * time.Minute
+
+func NewPurgeUnverifiedUsersTask() *asynq.Task {
+	return asynq.NewTask(CronPurgeUnverifiedUsers, nil)
+}
+
+// NewPurgeUnverifiedUsersHandler 删除注册超过 retention 仍未验证邮箱的用户
+func NewPurgeUnverifiedUsersHandler(db *gorm.DB, retention time.Duration) func(context.Context, *asynq.Task) error {
+	userRepo := repositories.NewUserRepository(db)
+	return func(ctx context.Context, t *asynq.Task) error {
+		// 每个实例的调度器都会触发任务，通过锁保证同一时间只有一个实例执行
+		err := cache.RunLocked(ctx, CronPurgeUnverifiedUsers, purgeLockTTL, 0, func(ctx context.Context) error {
+			purged, err := userRepo.PurgeUnverified(ctx, time.Now().Add(-retention))
+			if err != nil {
+				return fmt.Errorf("清理未验证用户失败: %w", err)
+			}
+			if purged > 0 {
+				log.Printf("Purged %d unverified users registered before %s", purged, time.Now().Add(-retention).Format(time.RFC3339))
+			}
+			return nil
+		})
+		if errors.Is(err, cache.ErrLockNotAcquired) {
+			log.Printf("Skip %s: already running on another instance", CronPurgeUnverifiedUsers)
+			return nil
+		}
+		return err
+	}
+}
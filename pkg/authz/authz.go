@@ -0,0 +1,88 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/utils"
+)
+
+// defaultPolicyFile 未配置时使用的策略文件，与 config.yaml 放在同一目录
+const defaultPolicyFile = "config/policies.yaml"
+
+var (
+	// ErrForbidden 策略拒绝访问
+	ErrForbidden = errors.New("没有权限执行该操作")
+	// ErrNotInitialized 策略引擎未初始化
+	ErrNotInitialized = errors.New("授权引擎未初始化")
+)
+
+var (
+	defaultEngine *Engine
+	mu            sync.RWMutex
+)
+
+// subjectKey 非 Gin 上下文中保存主体的键
+type subjectKey struct{}
+
+// Init 加载策略文件并初始化默认引擎
+func Init(cfg config.Authz) error {
+	path := cfg.PolicyFile
+	if path == "" {
+		path = defaultPolicyFile
+	}
+
+	engine, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	SetEngine(engine)
+	return nil
+}
+
+// SetEngine 设置默认引擎，便于测试替换
+func SetEngine(engine *Engine) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultEngine = engine
+}
+
+// GetEngine 获取默认引擎
+func GetEngine() *Engine {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultEngine
+}
+
+// WithSubject 将主体写入上下文，供队列任务等非 HTTP 场景调用 Authorize
+func WithSubject(ctx context.Context, subject *utils.UserInfo) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext 从上下文获取主体
+// 兼容 JWTAuth / OptionalJWTAuth 写入 gin.Context 的 "user"
+func SubjectFromContext(ctx context.Context) *utils.UserInfo {
+	if subject, ok := ctx.Value(subjectKey{}).(*utils.UserInfo); ok {
+		return subject
+	}
+	if subject, ok := ctx.Value("user").(*utils.UserInfo); ok {
+		return subject
+	}
+	return nil
+}
+
+// Authorize 使用上下文中的主体评估授权策略，拒绝时返回 ErrForbidden
+func Authorize(ctx context.Context, action string, resource Resource) error {
+	engine := GetEngine()
+	if engine == nil {
+		return ErrNotInitialized
+	}
+
+	if !engine.Evaluate(SubjectFromContext(ctx), action, resource).Allowed {
+		return ErrForbidden
+	}
+	return nil
+}
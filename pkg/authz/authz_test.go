@@ -0,0 +1,150 @@
+package authz
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// 策略重新加载时会写日志
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+const testPolicies = `
+policies:
+  - id: news-read-public
+    effect: allow
+    actions: ["news:read"]
+    resources: ["news"]
+    conditions:
+      - key: resource.visibility
+        operator: eq
+        value: public
+  - id: news-author-manage
+    effect: allow
+    actions: ["news:*"]
+    resources: ["news"]
+    conditions:
+      - key: resource.author_id
+        operator: eq
+        value: "{{subject.id}}"
+  - id: banned-deny
+    effect: deny
+    actions: ["*"]
+    resources: ["*"]
+    conditions:
+      - key: subject.roles
+        operator: contains
+        value: banned
+`
+
+func writePolicies(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入策略文件失败: %v", err)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	writePolicies(t, path, testPolicies)
+
+	engine, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	author := &utils.UserInfo{Id: "u1"}
+	other := &utils.UserInfo{Id: "u2"}
+	banned := &utils.UserInfo{Id: "u1", Roles: []string{"banned"}}
+	internal := NewResource("news", map[string]interface{}{"author_id": "u1", "visibility": "internal"})
+	public := NewResource("news", map[string]interface{}{"author_id": "u1", "visibility": "public"})
+	orphan := NewResource("news", map[string]interface{}{"author_id": "", "visibility": "internal"})
+
+	tests := []struct {
+		name     string
+		subject  *utils.UserInfo
+		action   string
+		resource Resource
+		allowed  bool
+	}{
+		{"匿名用户可以查看公开快讯", nil, "news:read", public, true},
+		{"匿名用户不能查看内部快讯", nil, "news:read", internal, false},
+		{"作者可以编辑自己的快讯", author, "news:update", internal, true},
+		{"其他用户不能编辑", other, "news:update", internal, false},
+		{"空属性不能匹配引用", nil, "news:update", orphan, false},
+		{"deny 策略优先", banned, "news:update", internal, false},
+		{"未匹配任何策略时拒绝", author, "user:delete", NewResource("user", nil), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.Evaluate(tt.subject, tt.action, tt.resource); got.Allowed != tt.allowed {
+				t.Errorf("期望 %v, 实际 %+v", tt.allowed, got)
+			}
+		})
+	}
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	_, err := NewEngine([]Policy{{ID: "bad", Effect: "permit", Actions: []string{"*"}, Resources: []string{"*"}}})
+	if err == nil {
+		t.Error("未知的 effect 应加载失败")
+	}
+}
+
+func TestHotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	writePolicies(t, path, testPolicies)
+
+	engine, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("加载策略失败: %v", err)
+	}
+
+	resource := NewResource("report", nil)
+	if engine.Evaluate(&utils.UserInfo{Id: "u1"}, "report:read", resource).Allowed {
+		t.Fatal("重新加载前不应允许")
+	}
+
+	writePolicies(t, path, `
+policies:
+  - id: report-read
+    effect: allow
+    actions: ["report:read"]
+    resources: ["report"]
+`)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if engine.Evaluate(&utils.UserInfo{Id: "u1"}, "report:read", resource).Allowed {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Error("策略文件修改后未自动重新加载")
+}
+
+func TestShippedPolicies(t *testing.T) {
+	engine, err := LoadFile("../../config/policies.yaml")
+	if err != nil {
+		t.Fatalf("加载默认策略失败: %v", err)
+	}
+
+	finance := &utils.UserInfo{Id: "u1", Roles: []string{"finance"}}
+	internal := NewResource("news", map[string]interface{}{"visibility": "internal"})
+	if !engine.Evaluate(finance, "news:read", internal).Allowed {
+		t.Error("财务团队应可以查看内部快讯")
+	}
+	if engine.Evaluate(&utils.UserInfo{Id: "u2"}, "news:read", internal).Allowed {
+		t.Error("普通用户不应查看内部快讯")
+	}
+}
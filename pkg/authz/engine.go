@@ -0,0 +1,134 @@
+package authz
+
+import (
+	"fmt"
+	"sync"
+
+	"mygoframe/pkg/logger"
+	"mygoframe/pkg/utils"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Decision 授权结果
+type Decision struct {
+	Allowed  bool
+	PolicyID string // 决定结果的策略，默认拒绝时为空
+}
+
+// Engine 策略引擎
+// 评估规则：任意匹配的 deny 策略优先（deny-overrides），其次是匹配的 allow 策略，没有策略匹配时默认拒绝
+type Engine struct {
+	mu       sync.RWMutex
+	policies []Policy
+	v        *viper.Viper
+}
+
+// NewEngine 创建策略引擎，policies 可为空
+func NewEngine(policies []Policy) (*Engine, error) {
+	e := &Engine{}
+	if err := e.SetPolicies(policies); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// LoadFile 从 YAML 文件加载策略，并监听文件变化自动重新加载
+// 使用独立的 viper 实例，不影响全局配置
+func LoadFile(path string) (*Engine, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("yaml")
+
+	e := &Engine{v: v}
+	if err := e.reload(); err != nil {
+		return nil, err
+	}
+
+	v.OnConfigChange(func(event fsnotify.Event) {
+		if err := e.reload(); err != nil {
+			// 新策略有误时保留旧策略，避免一次错误编辑导致全部拒绝或放行
+			logger.Error("重新加载授权策略失败，继续使用旧策略", zap.String("file", path), zap.Error(err))
+			return
+		}
+		logger.Info("授权策略已重新加载", zap.String("file", path), zap.Int("count", e.Len()))
+	})
+	v.WatchConfig()
+
+	return e, nil
+}
+
+func (e *Engine) reload() error {
+	if err := e.v.ReadInConfig(); err != nil {
+		return fmt.Errorf("读取授权策略文件失败: %w", err)
+	}
+
+	var policies []Policy
+	if err := e.v.UnmarshalKey("policies", &policies); err != nil {
+		return fmt.Errorf("解析授权策略失败: %w", err)
+	}
+	return e.SetPolicies(policies)
+}
+
+// SetPolicies 校验并替换全部策略
+func (e *Engine) SetPolicies(policies []Policy) error {
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	e.policies = policies
+	e.mu.Unlock()
+	return nil
+}
+
+// Len 返回当前策略数量
+func (e *Engine) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.policies)
+}
+
+// Evaluate 评估主体能否对资源执行操作，subject 为 nil 表示匿名访问
+func (e *Engine) Evaluate(subject *utils.UserInfo, action string, resource Resource) Decision {
+	req := request{
+		subject:  subjectAttributes(subject),
+		action:   action,
+		resource: resource,
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	decision := Decision{}
+	for _, policy := range e.policies {
+		if !policy.matches(req) {
+			continue
+		}
+		if policy.Effect == EffectDeny {
+			return Decision{Allowed: false, PolicyID: policy.ID}
+		}
+		if !decision.Allowed {
+			decision = Decision{Allowed: true, PolicyID: policy.ID}
+		}
+	}
+	return decision
+}
+
+// subjectAttributes 将用户信息转换为条件可引用的属性，匿名用户没有任何属性
+func subjectAttributes(subject *utils.UserInfo) map[string]interface{} {
+	if subject == nil || subject.Id == "" {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"id":           subject.Id,
+		"display_name": subject.DisplayName,
+		"email":        subject.Email,
+		"phone":        subject.Phone,
+		"roles":        subject.Roles,
+	}
+}
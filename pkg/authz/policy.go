@@ -0,0 +1,196 @@
+package authz
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 策略效果
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// 条件运算符
+const (
+	OpEq       = "eq"       // 属性等于值
+	OpNe       = "ne"       // 属性不等于值
+	OpIn       = "in"       // 属性属于值列表
+	OpNotIn    = "not_in"   // 属性不属于值列表
+	OpContains = "contains" // 列表属性包含值，例如 subject.roles 包含 admin
+	OpExists   = "exists"   // 属性存在（值为 true）或不存在（值为 false）
+)
+
+// Policy 一条授权策略
+// actions 与 resources 支持 "*" 通配以及 "news:*" 形式的前缀通配，所有条件同时满足时策略生效
+type Policy struct {
+	ID          string      `mapstructure:"id"`
+	Description string      `mapstructure:"description"`
+	Effect      string      `mapstructure:"effect"`
+	Actions     []string    `mapstructure:"actions"`
+	Resources   []string    `mapstructure:"resources"`
+	Conditions  []Condition `mapstructure:"conditions"`
+}
+
+// Condition 策略条件
+// key 形如 subject.id、resource.author_id；value 可以是字面量，也可以用 {{subject.id}} 引用其他属性
+type Condition struct {
+	Key      string      `mapstructure:"key"`
+	Operator string      `mapstructure:"operator"`
+	Value    interface{} `mapstructure:"value"`
+}
+
+// Resource 被访问的资源
+type Resource struct {
+	Type       string
+	Attributes map[string]interface{}
+}
+
+// NewResource 创建资源描述
+func NewResource(resourceType string, attributes map[string]interface{}) Resource {
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	return Resource{Type: resourceType, Attributes: attributes}
+}
+
+// request 一次授权请求的属性集合
+type request struct {
+	subject  map[string]interface{}
+	action   string
+	resource Resource
+}
+
+// validate 在加载时检查策略，避免拼写错误的策略被静默忽略
+func (p Policy) validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("策略缺少 id")
+	}
+	if p.Effect != EffectAllow && p.Effect != EffectDeny {
+		return fmt.Errorf("策略 %s 的 effect 必须是 allow 或 deny", p.ID)
+	}
+	if len(p.Actions) == 0 || len(p.Resources) == 0 {
+		return fmt.Errorf("策略 %s 必须配置 actions 和 resources", p.ID)
+	}
+	for _, cond := range p.Conditions {
+		switch cond.Operator {
+		case OpEq, OpNe, OpIn, OpNotIn, OpContains, OpExists:
+		default:
+			return fmt.Errorf("策略 %s 使用了未知的运算符: %s", p.ID, cond.Operator)
+		}
+		if !strings.HasPrefix(cond.Key, "subject.") && !strings.HasPrefix(cond.Key, "resource.") {
+			return fmt.Errorf("策略 %s 的条件键必须以 subject. 或 resource. 开头: %s", p.ID, cond.Key)
+		}
+	}
+	return nil
+}
+
+// matches 判断策略是否适用于本次请求
+func (p Policy) matches(req request) bool {
+	if !matchPattern(p.Actions, req.action) || !matchPattern(p.Resources, req.resource.Type) {
+		return false
+	}
+	for _, cond := range p.Conditions {
+		if !cond.evaluate(req) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchPattern(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(value, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate 计算条件，引用的属性不存在时条件不成立
+func (c Condition) evaluate(req request) bool {
+	actual, found := req.lookup(c.Key)
+
+	if c.Operator == OpExists {
+		want, _ := c.Value.(bool)
+		return found == want
+	}
+	if !found {
+		return false
+	}
+
+	expected, ok := req.resolve(c.Value)
+	if !ok {
+		return false
+	}
+
+	switch c.Operator {
+	case OpEq:
+		return equal(actual, expected)
+	case OpNe:
+		return !equal(actual, expected)
+	case OpIn:
+		return containsValue(expected, actual)
+	case OpNotIn:
+		return !containsValue(expected, actual)
+	case OpContains:
+		return containsValue(actual, expected)
+	default:
+		return false
+	}
+}
+
+// lookup 按 subject.xxx / resource.xxx 查找属性
+func (r request) lookup(key string) (interface{}, bool) {
+	var attrs map[string]interface{}
+	switch {
+	case strings.HasPrefix(key, "subject."):
+		attrs, key = r.subject, strings.TrimPrefix(key, "subject.")
+	case strings.HasPrefix(key, "resource."):
+		attrs, key = r.resource.Attributes, strings.TrimPrefix(key, "resource.")
+	default:
+		return nil, false
+	}
+
+	value, ok := attrs[key]
+	if !ok || value == nil || value == "" {
+		return nil, false
+	}
+	return value, true
+}
+
+// resolve 解析条件值中的 {{subject.id}} 引用
+func (r request) resolve(value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return value, true
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{{") && strings.HasSuffix(s, "}}") {
+		return r.lookup(strings.TrimSpace(s[2 : len(s)-2]))
+	}
+	return value, true
+}
+
+// equal 按字符串形式比较，使 YAML 中的数字与模型中的 uint 等类型可以比较
+func equal(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// containsValue 判断 list 是否包含 item，list 不是切片时按相等比较
+func containsValue(list interface{}, item interface{}) bool {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return equal(list, item)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if equal(v.Index(i).Interface(), item) {
+			return true
+		}
+	}
+	return false
+}
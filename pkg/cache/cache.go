@@ -71,6 +71,54 @@ func Flush(ctx context.Context) error {
 	return globalStore.Flush(ctx)
 }
 
+// Increment 原子地增加整数值，键不存在时从 0 开始并设置有效期
+func Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	if err := ensureInitialized(); err != nil {
+		return 0, err
+	}
+	return globalStore.Increment(ctx, key, by, ttl)
+}
+
+// Decrement 原子地减少整数值，键不存在时从 0 开始并设置有效期
+func Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	if err := ensureInitialized(); err != nil {
+		return 0, err
+	}
+	return globalStore.Decrement(ctx, key, by, ttl)
+}
+
+// Add 键不存在时写入并返回 true
+func Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	if err := ensureInitialized(); err != nil {
+		return false, err
+	}
+	return globalStore.Add(ctx, key, value, ttl)
+}
+
+// Pull 原子地读取并删除键
+func Pull(ctx context.Context, key string) (string, error) {
+	if err := ensureInitialized(); err != nil {
+		return "", err
+	}
+	return globalStore.Pull(ctx, key)
+}
+
+// Forever 写入永不过期的键
+func Forever(ctx context.Context, key string, value string) error {
+	if err := ensureInitialized(); err != nil {
+		return err
+	}
+	return globalStore.Forever(ctx, key, value)
+}
+
+// TTL 返回键的剩余有效期
+func TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ensureInitialized(); err != nil {
+		return 0, err
+	}
+	return globalStore.TTL(ctx, key)
+}
+
 func PutObject(ctx context.Context, key string, obj interface{}, ttl time.Duration) error {
 	if err := ensureInitialized(); err != nil {
 		return err
@@ -85,6 +133,37 @@ func GetObject(ctx context.Context, key string, obj interface{}) error {
 	return globalStore.GetObject(ctx, key, obj)
 }
 
+// Tags 返回带标签的缓存，用于按标签批量清除相关的键
+func Tags(names ...string) TaggedCache {
+	if globalStore == nil {
+		return newTaggedCache(uninitializedStore{}, names)
+	}
+	return globalStore.Tags(names...)
+}
+
+// Lock 返回互斥锁，启用 Redis 时在多个实例间互斥，否则只在进程内互斥
+func Lock(name string, ttl time.Duration) Locker {
+	if globalStore == nil {
+		return newLock(uninitializedStore{}, name, ttl)
+	}
+	return globalStore.Lock(name, ttl)
+}
+
+// RunLocked 获取锁 name 后执行 fn，执行期间自动续期，结束后释放锁
+// wait 大于 0 时最多等待 wait，否则锁被占用时立即返回 ErrLockNotAcquired
+func RunLocked(ctx context.Context, name string, ttl time.Duration, wait time.Duration, fn func(ctx context.Context) error) error {
+	return Lock(name, ttl).Run(ctx, wait, fn)
+}
+
+// Remember 读取缓存，未命中时调用 loader 加载并写入缓存
+// 缓存系统未初始化时直接调用 loader，不影响业务读取
+func Remember(ctx context.Context, key string, ttl time.Duration, loader Loader, opts ...RememberOption) (string, error) {
+	if err := ensureInitialized(); err != nil {
+		return loader(ctx)
+	}
+	return globalStore.Remember(ctx, key, ttl, loader, opts...)
+}
+
 // Store 获取指定名称的缓存存储
 func Store(storeName string) Repository {
 	if globalStore == nil {
@@ -103,6 +182,11 @@ func Redis() Repository {
 	return Store("redis")
 }
 
+// Tiered 获取两级缓存存储，未启用时回退到本地缓存
+func Tiered() Repository {
+	return Store("tiered")
+}
+
 // GetClient 获取指定缓存存储的底层客户端
 func GetClient(storeName string) interface{} {
 	store := Store(storeName)
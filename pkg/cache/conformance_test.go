@@ -0,0 +1,312 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+)
+
+// runConformance 各缓存驱动共用的行为测试，保证切换驱动时语义一致
+func runConformance(t *testing.T, newRepo func(t *testing.T) Repository) {
+	ctx := context.Background()
+
+	t.Run("GetPutForget", func(t *testing.T) {
+		repo := newRepo(t)
+		if _, err := repo.Get(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+			t.Errorf("Get 不存在的键 = %v, 期望 ErrCacheMiss", err)
+		}
+		if err := repo.Put(ctx, "k", "v", time.Minute); err != nil {
+			t.Fatalf("Put失败: %v", err)
+		}
+		if val, err := repo.Get(ctx, "k"); err != nil || val != "v" {
+			t.Errorf("Get = %q, %v, 期望 v", val, err)
+		}
+		if exists, _ := repo.Has(ctx, "k"); !exists {
+			t.Error("Has 应返回 true")
+		}
+		if err := repo.Forget(ctx, "k"); err != nil {
+			t.Fatalf("Forget失败: %v", err)
+		}
+		if exists, _ := repo.Has(ctx, "k"); exists {
+			t.Error("Forget 后 Has 应返回 false")
+		}
+	})
+
+	t.Run("Expiration", func(t *testing.T) {
+		repo := newRepo(t)
+		_ = repo.Put(ctx, "short", "v", 100*time.Millisecond)
+		time.Sleep(300 * time.Millisecond)
+		if _, err := repo.Get(ctx, "short"); !errors.Is(err, ErrCacheMiss) {
+			t.Errorf("过期后 Get = %v, 期望 ErrCacheMiss", err)
+		}
+	})
+
+	t.Run("Increment", func(t *testing.T) {
+		repo := newRepo(t)
+		if n, err := repo.Increment(ctx, "counter", 2, time.Minute); err != nil || n != 2 {
+			t.Fatalf("Increment = %d, %v, 期望 2", n, err)
+		}
+		if n, _ := repo.Increment(ctx, "counter", 3, time.Hour); n != 5 {
+			t.Errorf("Increment = %d, 期望 5", n)
+		}
+		// 已存在的键保持首次设置的有效期
+		if ttl, err := repo.TTL(ctx, "counter"); err != nil || ttl <= 0 || ttl > time.Minute {
+			t.Errorf("TTL = %v, %v, 期望不超过 1 分钟", ttl, err)
+		}
+		if n, _ := repo.Decrement(ctx, "counter", 1, time.Minute); n != 4 {
+			t.Errorf("Decrement = %d, 期望 4", n)
+		}
+		if n, _ := repo.Decrement(ctx, "new-counter", 1, 0); n != -1 {
+			t.Errorf("Decrement 不存在的键 = %d, 期望 -1", n)
+		}
+		if val, _ := repo.Get(ctx, "counter"); val != "4" {
+			t.Errorf("计数器的值 = %q, 期望 4", val)
+		}
+
+		_ = repo.Put(ctx, "text", "abc", time.Minute)
+		if _, err := repo.Increment(ctx, "text", 1, time.Minute); err == nil {
+			t.Error("非整数值 Increment 应返回错误")
+		}
+	})
+
+	t.Run("IncrementConcurrent", func(t *testing.T) {
+		repo := newRepo(t)
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := repo.Increment(ctx, "concurrent", 1, time.Minute); err != nil {
+					t.Errorf("Increment失败: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if val, _ := repo.Get(ctx, "concurrent"); val != "50" {
+			t.Errorf("并发累加结果 = %q, 期望 50", val)
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		repo := newRepo(t)
+		if added, err := repo.Add(ctx, "once", "first", time.Minute); err != nil || !added {
+			t.Fatalf("首次 Add = %v, %v, 期望 true", added, err)
+		}
+		if added, _ := repo.Add(ctx, "once", "second", time.Minute); added {
+			t.Error("键已存在时 Add 应返回 false")
+		}
+		if val, _ := repo.Get(ctx, "once"); val != "first" {
+			t.Errorf("Add 不应覆盖已有值: %q", val)
+		}
+
+		var succeeded int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if added, _ := repo.Add(ctx, "race", "v", time.Minute); added {
+					atomic.AddInt32(&succeeded, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		if succeeded != 1 {
+			t.Errorf("并发 Add 成功次数 = %d, 期望 1", succeeded)
+		}
+	})
+
+	t.Run("Pull", func(t *testing.T) {
+		repo := newRepo(t)
+		_ = repo.Put(ctx, "code", "123456", time.Minute)
+		if val, err := repo.Pull(ctx, "code"); err != nil || val != "123456" {
+			t.Fatalf("Pull = %q, %v, 期望 123456", val, err)
+		}
+		if _, err := repo.Pull(ctx, "code"); !errors.Is(err, ErrCacheMiss) {
+			t.Errorf("再次 Pull = %v, 期望 ErrCacheMiss", err)
+		}
+
+		_ = repo.Put(ctx, "race", "v", time.Minute)
+		var succeeded int32
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := repo.Pull(ctx, "race"); err == nil {
+					atomic.AddInt32(&succeeded, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		if succeeded != 1 {
+			t.Errorf("并发 Pull 成功次数 = %d, 期望 1", succeeded)
+		}
+	})
+
+	t.Run("ForeverAndTTL", func(t *testing.T) {
+		repo := newRepo(t)
+		if err := repo.Forever(ctx, "forever", "v"); err != nil {
+			t.Fatalf("Forever失败: %v", err)
+		}
+		if ttl, err := repo.TTL(ctx, "forever"); err != nil || ttl != NoExpiration {
+			t.Errorf("TTL = %v, %v, 期望 NoExpiration", ttl, err)
+		}
+
+		_ = repo.Put(ctx, "expiring", "v", time.Minute)
+		if ttl, err := repo.TTL(ctx, "expiring"); err != nil || ttl <= 0 || ttl > time.Minute {
+			t.Errorf("TTL = %v, %v, 期望 (0, 1m]", ttl, err)
+		}
+
+		if _, err := repo.TTL(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+			t.Errorf("TTL 不存在的键 = %v, 期望 ErrCacheMiss", err)
+		}
+	})
+
+	t.Run("Flush", func(t *testing.T) {
+		repo := newRepo(t)
+		_ = repo.Put(ctx, "a", "1", time.Minute)
+		_ = repo.Forever(ctx, "b", "2")
+		if err := repo.Flush(ctx); err != nil {
+			t.Fatalf("Flush失败: %v", err)
+		}
+		for _, key := range []string{"a", "b"} {
+			if exists, _ := repo.Has(ctx, key); exists {
+				t.Errorf("Flush 后 %s 不应存在", key)
+			}
+		}
+	})
+}
+
+func TestLocalRepositoryConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) Repository {
+		repo, err := NewLocalRepository(&config.Config{
+			LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+		})
+		if err != nil {
+			t.Fatalf("创建本地缓存失败: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+		return repo
+	})
+}
+
+func TestRedisRepositoryConformance(t *testing.T) {
+	newTestRedisConfig(t) // Redis 不可用时整体跳过
+	runConformance(t, func(t *testing.T) Repository {
+		repo, err := NewRedisRepository(newTestRedisConfig(t))
+		if err != nil {
+			t.Fatalf("连接Redis失败: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+		return repo
+	})
+}
+
+func TestTieredRepositoryConformance(t *testing.T) {
+	newTestRedisConfig(t)
+	runConformance(t, func(t *testing.T) Repository {
+		cfg := newTestRedisConfig(t)
+		redisRepo, err := NewRedisRepository(cfg)
+		if err != nil {
+			t.Fatalf("连接Redis失败: %v", err)
+		}
+		repo, err := NewTieredRepository(cfg, redisRepo)
+		if err != nil {
+			t.Fatalf("创建两级缓存失败: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = repo.Close()
+			_ = redisRepo.Close()
+		})
+		return repo
+	})
+}
+
+// TestLocalFlushConcurrent 验证 Flush 原地清空本地缓存：不泄漏 goroutine，与原子操作并发执行时不丢失写入
+func TestLocalFlushConcurrent(t *testing.T) {
+	repo, err := NewLocalRepository(&config.Config{
+		LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+	})
+	if err != nil {
+		t.Fatalf("创建本地缓存失败: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		_ = repo.Put(ctx, "k", "v", time.Minute)
+		if err := repo.Flush(ctx); err != nil {
+			t.Fatalf("Flush失败: %v", err)
+		}
+	}
+	// 被停止的后台 goroutine 可能尚未调度退出，等待其结束后再比较
+	after := runtime.NumGoroutine()
+	for deadline := time.Now().Add(time.Second); after > before+5 && time.Now().Before(deadline); after = runtime.NumGoroutine() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after > before+5 {
+		t.Errorf("多次 Flush 后 goroutine 数量 %d -> %d，旧缓存未释放", before, after)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = repo.Increment(ctx, "counter", 1, time.Minute)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = repo.Flush(ctx)
+		}()
+	}
+	wg.Wait()
+
+	// Flush 之后的写入对后续读取可见
+	_ = repo.Flush(ctx)
+	if n, err := repo.Increment(ctx, "counter", 1, time.Minute); err != nil || n != 1 {
+		t.Errorf("Flush 后 Increment = %d, %v, 期望 1", n, err)
+	}
+	if err := repo.Put(ctx, "after", "v", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+	if val, err := repo.Get(ctx, "after"); err != nil || val != "v" {
+		t.Errorf("Flush 后 Get = %q, %v, 期望 v", val, err)
+	}
+}
+
+// TestLocalPutDoesNotWaitForAtomicOps 验证 Put、Forget 与回填不经过原子操作的锁
+func TestLocalPutDoesNotWaitForAtomicOps(t *testing.T) {
+	repo, err := NewLocalRepository(&config.Config{
+		LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+	})
+	if err != nil {
+		t.Fatalf("创建本地缓存失败: %v", err)
+	}
+	defer repo.Close()
+	ctx := context.Background()
+
+	repo.opMu.Lock()
+	defer repo.opMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = repo.Put(ctx, "k", "v", time.Minute)
+		_ = repo.Forget(ctx, "k")
+		repo.fill("filled", "v", time.Minute)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Put、Forget 或回填被原子操作的锁阻塞")
+	}
+}
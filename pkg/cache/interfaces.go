@@ -2,15 +2,37 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrCacheMiss 缓存不存在或已过期
+var ErrCacheMiss = errors.New("缓存不存在")
+
+// NoExpiration TTL 对永不过期的键返回的值
+const NoExpiration time.Duration = -1
+
 type Repository interface {
 	Get(ctx context.Context, key string) (string, error)
 	Put(ctx context.Context, key string, value string, ttl time.Duration) error
 	Forget(ctx context.Context, key string) error
 	Has(ctx context.Context, key string) (bool, error)
 	Flush(ctx context.Context) error
+	// Increment 原子地将整数值增加 by 并返回新值，键不存在时从 0 开始并设置有效期 ttl（0 表示永久），已存在时保持原有效期
+	Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error)
+	// Decrement 原子地将整数值减少 by 并返回新值，有效期规则与 Increment 相同
+	Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error)
+	// Add 键不存在时写入并返回 true，已存在时不修改并返回 false
+	Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+	// Pull 原子地读取并删除键，键不存在时返回 ErrCacheMiss
+	Pull(ctx context.Context, key string) (string, error)
+	// Forever 写入永不过期的键
+	Forever(ctx context.Context, key string, value string) error
+	// TTL 返回键的剩余有效期，永不过期时返回 NoExpiration，键不存在时返回 ErrCacheMiss
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Tags(names ...string) TaggedCache
+	// Lock 返回名为 name、有效期为 ttl 的互斥锁，ttl 不大于 0 时使用默认有效期
+	Lock(name string, ttl time.Duration) Locker
 	Client() interface{}
 }
 
@@ -5,14 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"mygoframe/pkg/config"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
 )
 
 type LocalRepository struct {
+	opMu   sync.Mutex // 串行化 Increment、Add、Pull 等先读后写的操作，使其具有原子性；Put、Forget 直接写入不经过该锁
 	cache  *ristretto.Cache
 	prefix string
+
+	tagMu    sync.Mutex                     // 保护 tagIndex
+	tagIndex map[string]map[string]struct{} // 标签到键的索引，键过期后残留的索引在 Flush 时一并清除
+
+	lockMu sync.Mutex           // 保护 locks
+	locks  map[string]localLock // 进程内锁，不保存在 ristretto 中以免被淘汰或丢弃写入，也不受 Flush 影响
+}
+
+// localLock 进程内锁的持有者与到期时间
+type localLock struct {
+	owner     string
+	expiresAt time.Time
 }
 
 func NewLocalRepository(cfg *config.Config) (*LocalRepository, error) {
@@ -26,26 +41,32 @@ func NewLocalRepository(cfg *config.Config) (*LocalRepository, error) {
 		maxKeys = 1e6
 	}
 
-	cache, err := ristretto.NewCache(&ristretto.Config{
-		NumCounters: maxKeys,
-		MaxCost:     maxCost,
-		BufferItems: 64,
-	})
+	cache, err := newRistretto(maxCost, maxKeys)
 	if err != nil {
 		return nil, fmt.Errorf("创建本地缓存失败: %w", err)
 	}
 
 	return &LocalRepository{
-		cache:  cache,
-		prefix: "",
+		cache:    cache,
+		prefix:   "",
+		tagIndex: make(map[string]map[string]struct{}),
+		locks:    make(map[string]localLock),
 	}, nil
 }
 
+func newRistretto(maxCost int64, maxKeys int64) (*ristretto.Cache, error) {
+	return ristretto.NewCache(&ristretto.Config{
+		NumCounters: maxKeys,
+		MaxCost:     maxCost,
+		BufferItems: 64,
+	})
+}
+
 func (l *LocalRepository) Get(ctx context.Context, key string) (string, error) {
 	fullKey := l.prefix + key
 	val, found := l.cache.Get(fullKey)
 	if !found {
-		return "", errors.New("缓存不存在")
+		return "", ErrCacheMiss
 	}
 
 	strVal, ok := val.(string)
@@ -57,22 +78,115 @@ func (l *LocalRepository) Get(ctx context.Context, key string) (string, error) {
 }
 
 func (l *LocalRepository) Put(ctx context.Context, key string, value string, ttl time.Duration) error {
-	fullKey := l.prefix + key
-	cost := int64(len(value))
+	return l.set(l.cache, l.prefix+key, value, ttl)
+}
 
+// fill 回填缓存，不等待写入生效，写入被丢弃时下次读取会再次回填
+// 供两级缓存在 L1 未命中时使用，避免每次未命中都等待 ristretto 处理写入缓冲
+func (l *LocalRepository) fill(key string, value string, ttl time.Duration) {
+	cost := int64(len(value))
 	if ttl > 0 {
-		l.cache.SetWithTTL(fullKey, value, cost, ttl)
+		l.cache.SetWithTTL(l.prefix+key, value, cost, ttl)
 	} else {
-		l.cache.Set(fullKey, value, cost)
+		l.cache.Set(l.prefix+key, value, cost)
 	}
+}
 
-	l.cache.Wait()
+func (l *LocalRepository) Forget(ctx context.Context, key string) error {
+	l.cache.Del(l.prefix + key)
 	return nil
 }
 
-func (l *LocalRepository) Forget(ctx context.Context, key string) error {
+func (l *LocalRepository) Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	l.opMu.Lock()
+	defer l.opMu.Unlock()
+
+	cache := l.cache
 	fullKey := l.prefix + key
-	l.cache.Del(fullKey)
+
+	var current int64
+	if val, found := cache.Get(fullKey); found {
+		strVal, ok := val.(string)
+		n, err := strconv.ParseInt(strVal, 10, 64)
+		if !ok || err != nil {
+			return 0, errors.New("缓存值不是整数")
+		}
+		// 保持原有效期，GetTTL 对永不过期的键返回 0
+		// 读取后恰好过期时 GetTTL 返回 not found，此时视为新键，从 0 开始并使用调用方的有效期
+		if remaining, ok := cache.GetTTL(fullKey); ok {
+			current = n
+			ttl = remaining
+		}
+	}
+
+	current += by
+	if err := l.set(cache, fullKey, strconv.FormatInt(current, 10), ttl); err != nil {
+		return 0, err
+	}
+	return current, nil
+}
+
+func (l *LocalRepository) Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return l.Increment(ctx, key, -by, ttl)
+}
+
+func (l *LocalRepository) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	l.opMu.Lock()
+	defer l.opMu.Unlock()
+
+	cache := l.cache
+	fullKey := l.prefix + key
+	if _, found := cache.Get(fullKey); found {
+		return false, nil
+	}
+	if err := l.set(cache, fullKey, value, ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (l *LocalRepository) Pull(ctx context.Context, key string) (string, error) {
+	l.opMu.Lock()
+	defer l.opMu.Unlock()
+
+	val, err := l.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	l.cache.Del(l.prefix + key)
+	return val, nil
+}
+
+func (l *LocalRepository) Forever(ctx context.Context, key string, value string) error {
+	return l.Put(ctx, key, value, 0)
+}
+
+func (l *LocalRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, found := l.cache.GetTTL(l.prefix + key)
+	if !found {
+		return 0, ErrCacheMiss
+	}
+	if ttl == 0 {
+		return NoExpiration, nil
+	}
+	return ttl, nil
+}
+
+// set 写入并等待生效，保证写入后立即可读，ristretto 在写入缓冲已满或准入策略拒绝时会丢弃写入
+func (l *LocalRepository) set(cache *ristretto.Cache, fullKey string, value string, ttl time.Duration) error {
+	cost := int64(len(value))
+
+	var ok bool
+	if ttl > 0 {
+		ok = cache.SetWithTTL(fullKey, value, cost, ttl)
+	} else {
+		ok = cache.Set(fullKey, value, cost)
+	}
+	if !ok {
+		return errors.New("写入本地缓存失败")
+	}
+
+	cache.Wait()
 	return nil
 }
 
@@ -82,23 +196,100 @@ func (l *LocalRepository) Has(ctx context.Context, key string) (bool, error) {
 	return found, nil
 }
 
+// Flush 原地清空本地缓存，两级缓存收到其他实例的清空通知时同样会调用
+// 持有 opMu，避免与 Increment、Add、Pull 交错执行
 func (l *LocalRepository) Flush(ctx context.Context) error {
-	maxCost := int64(1 << 30)
-	maxCounters := int64(1e6)
+	l.opMu.Lock()
+	l.cache.Clear()
+	l.opMu.Unlock()
 
-	newCache, err := ristretto.NewCache(&ristretto.Config{
-		NumCounters: maxCounters,
-		MaxCost:     maxCost,
-		BufferItems: 64,
-	})
-	if err != nil {
-		return fmt.Errorf("重新创建本地缓存失败: %w", err)
+	l.tagMu.Lock()
+	l.tagIndex = make(map[string]map[string]struct{})
+	l.tagMu.Unlock()
+	return nil
+}
+
+// Tags 返回带标签的缓存，标签索引保存在进程内存中
+func (l *LocalRepository) Tags(names ...string) TaggedCache {
+	return newTaggedCache(l, names)
+}
+
+func (l *LocalRepository) putTagged(ctx context.Context, tags []string, key string, value string, ttl time.Duration) error {
+	if err := l.Put(ctx, key, value, ttl); err != nil {
+		return err
 	}
 
-	l.cache = newCache
+	l.tagMu.Lock()
+	defer l.tagMu.Unlock()
+	for _, tag := range tags {
+		keys, ok := l.tagIndex[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			l.tagIndex[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
 	return nil
 }
 
+func (l *LocalRepository) flushTags(ctx context.Context, tags []string) error {
+	l.tagMu.Lock()
+	var keys []string
+	for _, tag := range tags {
+		for key := range l.tagIndex[tag] {
+			keys = append(keys, key)
+		}
+		delete(l.tagIndex, tag)
+	}
+	l.tagMu.Unlock()
+
+	for _, key := range keys {
+		_ = l.Forget(ctx, key)
+	}
+	return nil
+}
+
+// Lock 返回进程内锁，只在当前进程内互斥
+func (l *LocalRepository) Lock(name string, ttl time.Duration) Locker {
+	return newLock(l, name, ttl)
+}
+
+func (l *LocalRepository) acquireLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	l.lockMu.Lock()
+	defer l.lockMu.Unlock()
+
+	if held, ok := l.locks[name]; ok && time.Now().Before(held.expiresAt) {
+		return false, nil
+	}
+	l.locks[name] = localLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (l *LocalRepository) releaseLock(ctx context.Context, name string, owner string) (bool, error) {
+	l.lockMu.Lock()
+	defer l.lockMu.Unlock()
+
+	held, ok := l.locks[name]
+	if !ok || held.owner != owner {
+		return false, nil
+	}
+	delete(l.locks, name)
+	// 已过期的锁同样删除，但返回未持有
+	return time.Now().Before(held.expiresAt), nil
+}
+
+func (l *LocalRepository) refreshLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	l.lockMu.Lock()
+	defer l.lockMu.Unlock()
+
+	held, ok := l.locks[name]
+	if !ok || held.owner != owner || !time.Now().Before(held.expiresAt) {
+		return false, nil
+	}
+	l.locks[name] = localLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
 func (l *LocalRepository) Close() error {
 	if l.cache != nil {
 		l.cache.Close()
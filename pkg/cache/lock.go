@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"mygoframe/pkg/logger"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLockNotAcquired 锁被其他持有者占用，或等待超时仍未获得
+	ErrLockNotAcquired = errors.New("锁已被其他持有者占用")
+	// ErrLockNotHeld 锁未被当前持有者持有，可能已过期或已释放
+	ErrLockNotHeld = errors.New("锁未持有或已过期")
+)
+
+// 锁相关的默认参数
+const (
+	defaultLockTTL    = 30 * time.Second      // 未指定有效期时锁的有效期
+	lockRetryInterval = 50 * time.Millisecond // Block 等待期间重试获取的间隔
+	lockKeyPrefix     = "lock:"               // 锁的键前缀
+)
+
+// Locker 互斥锁，启用 Redis 时在多个实例间互斥，否则只在进程内互斥
+// 每个 Locker 有独立的持有者令牌，只有获得锁的 Locker 才能释放或续期；锁到期后自动释放，避免持有者崩溃导致死锁
+type Locker interface {
+	// Acquire 尝试获取锁，锁被占用时立即返回 false
+	Acquire(ctx context.Context) (bool, error)
+	// Block 在 timeout 内等待获取锁，超时返回 ErrLockNotAcquired；timeout 不大于 0 时一直等待到 ctx 取消
+	Block(ctx context.Context, timeout time.Duration) error
+	// Release 释放锁，锁已过期或被其他持有者获取时返回 ErrLockNotHeld
+	Release(ctx context.Context) error
+	// Refresh 将锁的有效期重置为 ttl，锁已过期或被其他持有者获取时返回 ErrLockNotHeld
+	Refresh(ctx context.Context) error
+	// Run 获取锁后执行 fn，执行期间自动续期，结束后释放锁
+	// wait 大于 0 时最多等待 wait，否则锁被占用时立即返回 ErrLockNotAcquired
+	Run(ctx context.Context, wait time.Duration, fn func(ctx context.Context) error) error
+}
+
+// lockStore 支持锁的缓存存储，锁只在持有者令牌匹配时释放或续期
+type lockStore interface {
+	acquireLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+	releaseLock(ctx context.Context, name string, owner string) (bool, error)
+	refreshLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error)
+}
+
+// lock 各存储共用的 Locker 实现
+type lock struct {
+	store lockStore
+	name  string
+	owner string
+	ttl   time.Duration
+}
+
+func newLock(store lockStore, name string, ttl time.Duration) *lock {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	return &lock{store: store, name: name, owner: uuid.New().String(), ttl: ttl}
+}
+
+func (l *lock) Acquire(ctx context.Context) (bool, error) {
+	return l.store.acquireLock(ctx, l.name, l.owner, l.ttl)
+}
+
+func (l *lock) Block(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrLockNotAcquired)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := l.Acquire(ctx)
+		if acquired {
+			return nil
+		}
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			// 等待超时返回 ErrLockNotAcquired，调用方取消时返回 ctx 的错误
+			if errors.Is(context.Cause(ctx), ErrLockNotAcquired) {
+				return ErrLockNotAcquired
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *lock) Release(ctx context.Context) error {
+	released, err := l.store.releaseLock(ctx, l.name, l.owner)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (l *lock) Refresh(ctx context.Context) error {
+	refreshed, err := l.store.refreshLock(ctx, l.name, l.owner, l.ttl)
+	if err != nil {
+		return err
+	}
+	if !refreshed {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Run 续期失败说明锁已丢失，此时取消传给 fn 的 ctx，fn 应及时停止
+func (l *lock) Run(ctx context.Context, wait time.Duration, fn func(ctx context.Context) error) error {
+	if wait > 0 {
+		if err := l.Block(ctx, wait); err != nil {
+			return err
+		}
+	} else {
+		acquired, err := l.Acquire(ctx)
+		if err != nil {
+			return err
+		}
+		if !acquired {
+			return ErrLockNotAcquired
+		}
+	}
+	// 释放锁不受调用方取消影响
+	defer func() {
+		if err := l.Release(context.WithoutCancel(ctx)); err != nil {
+			logger.Warn("释放锁失败", zap.String("name", l.name), zap.Error(err))
+		}
+	}()
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.keepAlive(fnCtx, cancel)
+	}()
+	// 先停止续期再释放锁
+	defer func() {
+		cancel(nil)
+		wg.Wait()
+	}()
+
+	return fn(fnCtx)
+}
+
+// keepAlive 每隔三分之一有效期续期一次，直到 ctx 结束；续期失败时以该错误取消 ctx
+func (l *lock) keepAlive(ctx context.Context, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Refresh(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				logger.Warn("锁续期失败，取消执行", zap.String("name", l.name), zap.Error(err))
+				cancel(err)
+				return
+			}
+		}
+	}
+}
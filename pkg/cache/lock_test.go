@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+)
+
+// testLock 验证锁的互斥、持有者校验、到期与续期
+func testLock(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	a, b := repo.Lock("order:1", time.Minute), repo.Lock("order:1", time.Minute)
+	if acquired, err := a.Acquire(ctx); err != nil || !acquired {
+		t.Fatalf("Acquire = %v, %v, 期望 true", acquired, err)
+	}
+	if acquired, _ := b.Acquire(ctx); acquired {
+		t.Error("锁被占用时 Acquire 应返回 false")
+	}
+	// 只有持有者能释放或续期
+	if err := b.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("非持有者 Release = %v, 期望 ErrLockNotHeld", err)
+	}
+	if err := b.Refresh(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("非持有者 Refresh = %v, 期望 ErrLockNotHeld", err)
+	}
+	if err := a.Release(ctx); err != nil {
+		t.Fatalf("Release失败: %v", err)
+	}
+	if acquired, _ := b.Acquire(ctx); !acquired {
+		t.Error("释放后应能获取锁")
+	}
+	_ = b.Release(ctx)
+
+	// 到期后锁自动释放，原持有者不能再释放或续期
+	short := repo.Lock("order:2", 100*time.Millisecond)
+	if acquired, _ := short.Acquire(ctx); !acquired {
+		t.Fatal("Acquire 应返回 true")
+	}
+	time.Sleep(200 * time.Millisecond)
+	other := repo.Lock("order:2", time.Minute)
+	if acquired, _ := other.Acquire(ctx); !acquired {
+		t.Error("锁到期后应能获取")
+	}
+	if err := short.Refresh(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("过期后 Refresh = %v, 期望 ErrLockNotHeld", err)
+	}
+	if err := short.Release(ctx); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("过期后 Release = %v, 期望 ErrLockNotHeld", err)
+	}
+	_ = other.Release(ctx)
+
+	// 续期后锁在原到期时间之后仍被持有
+	refreshed := repo.Lock("order:3", 200*time.Millisecond)
+	_, _ = refreshed.Acquire(ctx)
+	time.Sleep(120 * time.Millisecond)
+	if err := refreshed.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh失败: %v", err)
+	}
+	time.Sleep(120 * time.Millisecond)
+	if acquired, _ := repo.Lock("order:3", time.Minute).Acquire(ctx); acquired {
+		t.Error("续期后锁不应被其他持有者获取")
+	}
+	_ = refreshed.Release(ctx)
+}
+
+// testLockBlock 验证等待获取锁的超时与取消
+func testLockBlock(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	holder := repo.Lock("job", time.Minute)
+	_, _ = holder.Acquire(ctx)
+
+	if err := repo.Lock("job", time.Minute).Block(ctx, 100*time.Millisecond); !errors.Is(err, ErrLockNotAcquired) {
+		t.Errorf("等待超时 = %v, 期望 ErrLockNotAcquired", err)
+	}
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := repo.Lock("job", time.Minute).Block(cancelCtx, time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后 = %v, 期望 context.Canceled", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = holder.Release(ctx)
+	}()
+	waiter := repo.Lock("job", time.Minute)
+	if err := waiter.Block(ctx, 2*time.Second); err != nil {
+		t.Fatalf("释放后 Block 应获得锁: %v", err)
+	}
+	_ = waiter.Release(ctx)
+}
+
+// testLockRun 验证 Run 互斥执行、自动续期并在结束后释放锁
+func testLockRun(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	var running, maxRunning, total int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.Lock("register:a@example.com", time.Minute).Run(ctx, 5*time.Second, func(ctx context.Context) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					current := atomic.LoadInt32(&maxRunning)
+					if n <= current || atomic.CompareAndSwapInt32(&maxRunning, current, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				atomic.AddInt32(&total, 1)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Run失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxRunning != 1 || total != 10 {
+		t.Errorf("最大并发 = %d, 执行次数 = %d, 期望 1 和 10", maxRunning, total)
+	}
+
+	// fn 的错误原样返回，结束后锁已释放
+	errFn := errors.New("执行失败")
+	if err := repo.Lock("cron", time.Minute).Run(ctx, 0, func(ctx context.Context) error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("Run = %v, 期望返回 fn 的错误", err)
+	}
+
+	// 执行时间超过有效期时自动续期，其他持有者无法获取；wait 为 0 时立即返回
+	err := repo.Lock("cron", 150*time.Millisecond).Run(ctx, 0, func(ctx context.Context) error {
+		time.Sleep(400 * time.Millisecond)
+		err := repo.Lock("cron", time.Minute).Run(ctx, 0, func(ctx context.Context) error { return nil })
+		if !errors.Is(err, ErrLockNotAcquired) {
+			t.Errorf("续期期间 Run = %v, 期望 ErrLockNotAcquired", err)
+		}
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Errorf("Run失败: %v", err)
+	}
+	if acquired, _ := repo.Lock("cron", time.Minute).Acquire(ctx); !acquired {
+		t.Error("Run 结束后锁应已释放")
+	}
+}
+
+func TestLocalLock(t *testing.T) {
+	newRepo := func(t *testing.T) Repository {
+		repo, err := NewLocalRepository(&config.Config{
+			LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+		})
+		if err != nil {
+			t.Fatalf("创建本地缓存失败: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+		return repo
+	}
+
+	testLock(t, newRepo(t))
+	testLockBlock(t, newRepo(t))
+	testLockRun(t, newRepo(t))
+}
+
+func TestRedisLock(t *testing.T) {
+	cfg := newTestRedisConfig(t)
+	repo, err := NewRedisRepository(cfg)
+	if err != nil {
+		t.Fatalf("连接Redis失败: %v", err)
+	}
+	defer repo.Close()
+
+	testLock(t, repo)
+	testLockBlock(t, repo)
+	testLockRun(t, repo)
+}
@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"mygoframe/pkg/config"
 	"mygoframe/pkg/logger"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 type Manager struct {
 	config       *config.Config
 	stores       map[string]Repository
 	defaultStore string
+	group        singleflight.Group // 合并 Remember 中同一个键的并发加载
+	refreshing   sync.Map           // 正在后台刷新的键
 }
 
 func NewManager(cfg *config.Config) *Manager {
@@ -56,6 +60,16 @@ func (m *Manager) Init() error {
 			return nil
 		}
 		m.stores["redis"] = redisRepo
+
+		if m.config.TieredCache.Enabled {
+			tieredRepo, err := NewTieredRepository(m.config, redisRepo)
+			if err != nil {
+				logger.Warn("两级缓存初始化失败，使用Redis缓存", zap.Error(err))
+				return nil
+			}
+			m.stores["tiered"] = tieredRepo
+			m.defaultStore = "tiered"
+		}
 	} else {
 		m.defaultStore = "local"
 	}
@@ -83,6 +97,30 @@ func (m *Manager) Flush(ctx context.Context) error {
 	return m.Store(m.defaultStore).Flush(ctx)
 }
 
+func (m *Manager) Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return m.Store(m.defaultStore).Increment(ctx, key, by, ttl)
+}
+
+func (m *Manager) Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return m.Store(m.defaultStore).Decrement(ctx, key, by, ttl)
+}
+
+func (m *Manager) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	return m.Store(m.defaultStore).Add(ctx, key, value, ttl)
+}
+
+func (m *Manager) Pull(ctx context.Context, key string) (string, error) {
+	return m.Store(m.defaultStore).Pull(ctx, key)
+}
+
+func (m *Manager) Forever(ctx context.Context, key string, value string) error {
+	return m.Store(m.defaultStore).Forever(ctx, key, value)
+}
+
+func (m *Manager) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return m.Store(m.defaultStore).TTL(ctx, key)
+}
+
 func (m *Manager) PutObject(ctx context.Context, key string, obj interface{}, ttl time.Duration) error {
 	data, err := SerializeObject(obj)
 	if err != nil {
@@ -102,6 +140,15 @@ func (m *Manager) GetObject(ctx context.Context, key string, obj interface{}) er
 func (m *Manager) Close() error {
 	var errs []error
 
+	// 两级缓存依赖 Redis 连接，需先于 Redis 关闭
+	if tieredStore, exists := m.stores["tiered"]; exists {
+		if closer, ok := tieredStore.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
 	if redisStore, exists := m.stores["redis"]; exists {
 		if closer, ok := redisStore.(interface{ Close() error }); ok {
 			if err := closer.Close(); err != nil {
@@ -124,6 +171,21 @@ func (m *Manager) Close() error {
 	return nil
 }
 
+// Tags 返回默认缓存存储的带标签缓存
+func (m *Manager) Tags(names ...string) TaggedCache {
+	return m.Store(m.defaultStore).Tags(names...)
+}
+
+// Lock 返回默认缓存存储的互斥锁
+func (m *Manager) Lock(name string, ttl time.Duration) Locker {
+	return m.Store(m.defaultStore).Lock(name, ttl)
+}
+
+// RunLocked 持有锁 name 时执行 fn，行为与 Locker.Run 相同
+func (m *Manager) RunLocked(ctx context.Context, name string, ttl time.Duration, wait time.Duration, fn func(ctx context.Context) error) error {
+	return m.Lock(name, ttl).Run(ctx, wait, fn)
+}
+
 // Client 返回默认缓存存储的底层客户端
 func (m *Manager) Client() interface{} {
 	return m.Store(m.defaultStore).Client()
@@ -2,14 +2,63 @@ package cache
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"mygoframe/pkg/config"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// tagKeyPrefix 标签集合的键前缀，集合中保存带有该标签的完整键名
+const tagKeyPrefix = "tag:"
+
+// tagFlushBatchSize 清除标签时每批删除的键数量
+const tagFlushBatchSize = 500
+
+// putTaggedScript 写入值并将键加入各标签集合
+// 标签集合的有效期不短于其中最晚过期的键，包含永久键时集合也永久保存
+// KEYS[1] 为值的键，其余为标签集合；ARGV[1] 为值，ARGV[2] 为有效期(毫秒)，0 表示永久
+var putTaggedScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call("EXISTS", KEYS[i])
+	redis.call("SADD", KEYS[i], KEYS[1])
+	if ttl == 0 then
+		redis.call("PERSIST", KEYS[i])
+	elseif existed == 0 then
+		redis.call("PEXPIRE", KEYS[i], ttl)
+	else
+		local current = redis.call("PTTL", KEYS[i])
+		if current >= 0 and current < ttl then
+			redis.call("PEXPIRE", KEYS[i], ttl)
+		end
+	end
+end
+return 1
+`)
+
+// releaseLockScript 只有锁的持有者才能释放锁
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshLockScript 只有锁的持有者才能续期，ARGV[2] 为新的有效期(毫秒)
+var refreshLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
 type RedisRepository struct {
 	client *redis.Client
 	prefix string
@@ -46,7 +95,7 @@ func (r *RedisRepository) Get(ctx context.Context, key string) (string, error) {
 	fullKey := r.prefix + key
 	val, err := r.client.Get(ctx, fullKey).Result()
 	if err == redis.Nil {
-		return "", errors.New("缓存不存在")
+		return "", ErrCacheMiss
 	}
 	if err != nil {
 		return "", err
@@ -99,6 +148,140 @@ func (r *RedisRepository) Flush(ctx context.Context) error {
 	return nil
 }
 
+// incrementScript 键不存在时先以有效期创建为 0，再原子地增加
+// KEYS[1] 为键；ARGV[1] 为增量，ARGV[2] 为有效期(毫秒)，0 表示永久
+var incrementScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], 0, "PX", ttl, "NX")
+end
+return redis.call("INCRBY", KEYS[1], ARGV[1])
+`)
+
+func (r *RedisRepository) Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return incrementScript.Run(ctx, r.client, []string{r.prefix + key}, by, ttl.Milliseconds()).Int64()
+}
+
+func (r *RedisRepository) Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return r.Increment(ctx, key, -by, ttl)
+}
+
+func (r *RedisRepository) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.prefix+key, value, ttl).Result()
+}
+
+func (r *RedisRepository) Pull(ctx context.Context, key string) (string, error) {
+	val, err := r.client.GetDel(ctx, r.prefix+key).Result()
+	if err == redis.Nil {
+		return "", ErrCacheMiss
+	}
+	if err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func (r *RedisRepository) Forever(ctx context.Context, key string, value string) error {
+	return r.Put(ctx, key, value, 0)
+}
+
+func (r *RedisRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, r.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL 对不存在的键返回 -2，对永不过期的键返回 -1，go-redis 原样返回这两个值
+	switch ttl {
+	case -2:
+		return 0, ErrCacheMiss
+	case -1:
+		return NoExpiration, nil
+	}
+	return ttl, nil
+}
+
+// Tags 返回带标签的缓存，每个标签对应一个保存键名的 Redis 集合
+func (r *RedisRepository) Tags(names ...string) TaggedCache {
+	return newTaggedCache(r, names)
+}
+
+func (r *RedisRepository) putTagged(ctx context.Context, tags []string, key string, value string, ttl time.Duration) error {
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, r.prefix+key)
+	for _, tag := range tags {
+		keys = append(keys, r.tagKey(tag))
+	}
+	return putTaggedScript.Run(ctx, r.client, keys, value, ttl.Milliseconds()).Err()
+}
+
+func (r *RedisRepository) flushTags(ctx context.Context, tags []string) error {
+	_, err := r.flushTaggedKeys(ctx, tags)
+	return err
+}
+
+// flushTaggedKeys 删除带有任一标签的键及标签集合，返回被删除的键（不含前缀）
+func (r *RedisRepository) flushTaggedKeys(ctx context.Context, tags []string) ([]string, error) {
+	var flushed []string
+	for _, tag := range tags {
+		tagKey := r.tagKey(tag)
+
+		var cursor uint64
+		for {
+			members, next, err := r.client.SScan(ctx, tagKey, cursor, "", tagFlushBatchSize).Result()
+			if err != nil {
+				return flushed, err
+			}
+			if len(members) > 0 {
+				if err := r.client.Del(ctx, members...).Err(); err != nil {
+					return flushed, err
+				}
+				for _, member := range members {
+					flushed = append(flushed, strings.TrimPrefix(member, r.prefix))
+				}
+			}
+
+			cursor = next
+			if cursor == 0 {
+				break
+			}
+		}
+
+		if err := r.client.Del(ctx, tagKey).Err(); err != nil {
+			return flushed, err
+		}
+	}
+	return flushed, nil
+}
+
+// tagKey 标签集合的完整键名
+func (r *RedisRepository) tagKey(tag string) string {
+	return r.prefix + tagKeyPrefix + tag
+}
+
+// Lock 返回跨实例的分布式锁，通过 SET NX PX 写入持有者令牌获取，通过脚本校验令牌后释放或续期
+func (r *RedisRepository) Lock(name string, ttl time.Duration) Locker {
+	return newLock(r, name, ttl)
+}
+
+func (r *RedisRepository) acquireLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.lockKey(name), owner, ttl).Result()
+}
+
+func (r *RedisRepository) releaseLock(ctx context.Context, name string, owner string) (bool, error) {
+	n, err := releaseLockScript.Run(ctx, r.client, []string{r.lockKey(name)}, owner).Int64()
+	return n == 1, err
+}
+
+func (r *RedisRepository) refreshLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	n, err := refreshLockScript.Run(ctx, r.client, []string{r.lockKey(name)}, owner, ttl.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+// lockKey 锁的完整键名
+func (r *RedisRepository) lockKey(name string) string {
+	return r.prefix + lockKeyPrefix + name
+}
+
 func (r *RedisRepository) Close() error {
 	return r.client.Close()
 }
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"mygoframe/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Remember 相关的默认参数
+const (
+	defaultLoadLockTTL   = 10 * time.Second      // 跨实例加载锁的有效期，应长于一次加载的耗时
+	defaultLoadLockWait  = 3 * time.Second       // 未获得加载锁时等待其他实例写入缓存的最长时间
+	loadLockPollInterval = 50 * time.Millisecond // 等待期间检查缓存的间隔
+	refreshTimeout       = 30 * time.Second      // 后台刷新的超时时间
+	freshKeySuffix       = ":fresh"              // 启用过期后返回旧值时，标记数据仍新鲜的键后缀
+	loadLockNamePrefix   = "remember:"           // 跨实例加载锁的名称前缀
+)
+
+// Loader 缓存未命中时加载数据
+type Loader func(ctx context.Context) (string, error)
+
+// rememberOptions Remember 的可选行为
+type rememberOptions struct {
+	staleTTL time.Duration
+	lock     bool
+	lockTTL  time.Duration
+	lockWait time.Duration
+}
+
+// RememberOption Remember 的选项
+type RememberOption func(*rememberOptions)
+
+// WithStale 启用过期后返回旧值（stale-while-revalidate）
+// 数据超过 ttl 后的 stale 时长内仍返回旧值，同时由一个后台任务刷新缓存
+func WithStale(stale time.Duration) RememberOption {
+	return func(o *rememberOptions) {
+		o.staleTTL = stale
+	}
+}
+
+// WithLock 缓存未命中时通过 Redis 锁保证多个实例中只有一个执行加载，其他实例等待结果
+// 未启用 Redis 时只在进程内合并并发加载
+func WithLock() RememberOption {
+	return func(o *rememberOptions) {
+		o.lock = true
+	}
+}
+
+// WithLockTimeout 设置加载锁的有效期和未获得锁时的最长等待时间，隐含 WithLock
+func WithLockTimeout(ttl time.Duration, wait time.Duration) RememberOption {
+	return func(o *rememberOptions) {
+		o.lock = true
+		o.lockTTL = ttl
+		o.lockWait = wait
+	}
+}
+
+// Remember 读取缓存，未命中时调用 loader 加载并写入缓存
+// 同一进程内对同一个键的并发未命中只执行一次 loader，loader 返回错误时不写入缓存
+func (m *Manager) Remember(ctx context.Context, key string, ttl time.Duration, loader Loader, opts ...RememberOption) (string, error) {
+	o := rememberOptions{
+		lockTTL:  defaultLoadLockTTL,
+		lockWait: defaultLoadLockWait,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if val, err := m.Get(ctx, key); err == nil {
+		if o.staleTTL > 0 {
+			if fresh, _ := m.Has(ctx, key+freshKeySuffix); !fresh {
+				m.refreshInBackground(ctx, key, ttl, loader, o)
+			}
+		}
+		return val, nil
+	}
+
+	// 加载由第一个请求发起，与各请求的取消信号解耦，避免一个请求取消导致其他等待的请求全部失败
+	result := m.group.DoChan(key, func() (interface{}, error) {
+		return m.load(context.WithoutCancel(ctx), key, ttl, loader, o)
+	})
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// load 加载数据并写入缓存，启用加载锁时先获取锁
+func (m *Manager) load(ctx context.Context, key string, ttl time.Duration, loader Loader, o rememberOptions) (string, error) {
+	if o.lock {
+		l, acquired, err := m.acquireLoadLock(ctx, key, o.lockTTL)
+		if err != nil {
+			logger.Warn("获取缓存加载锁失败，直接加载", zap.String("key", key), zap.Error(err))
+		}
+		if acquired {
+			defer m.releaseLoadLock(ctx, key, l)
+			// 获得锁前其他实例可能已经写入缓存
+			if val, err := m.Get(ctx, key); err == nil {
+				return val, nil
+			}
+		} else if err == nil && l != nil {
+			// 锁被其他实例持有，等待其写入缓存，超时后自行加载
+			if val, ok := m.waitForValue(ctx, key, o.lockWait); ok {
+				return val, nil
+			}
+		}
+	}
+
+	val, err := loader(ctx)
+	if err != nil {
+		return "", err
+	}
+	m.store(ctx, key, val, ttl, o)
+	return val, nil
+}
+
+// store 写入加载结果，启用过期后返回旧值时数据多保留 stale 时长，并单独记录新鲜期
+func (m *Manager) store(ctx context.Context, key string, val string, ttl time.Duration, o rememberOptions) {
+	cacheTTL := ttl
+	if o.staleTTL > 0 && ttl > 0 {
+		cacheTTL = ttl + o.staleTTL
+	}
+	if err := m.Put(ctx, key, val, cacheTTL); err != nil {
+		logger.Warn("写入缓存失败", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if o.staleTTL > 0 {
+		_ = m.Put(ctx, key+freshKeySuffix, "1", ttl)
+	}
+}
+
+// refreshInBackground 数据已过新鲜期时在后台刷新，同一进程内同一个键只有一个刷新任务
+// 启用加载锁时未获得锁的实例跳过刷新，由持有锁的实例完成
+func (m *Manager) refreshInBackground(ctx context.Context, key string, ttl time.Duration, loader Loader, o rememberOptions) {
+	if _, running := m.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer m.refreshing.Delete(key)
+
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+
+		if o.lock {
+			l, acquired, err := m.acquireLoadLock(refreshCtx, key, o.lockTTL)
+			if err == nil && l != nil && !acquired {
+				return
+			}
+			if acquired {
+				defer m.releaseLoadLock(refreshCtx, key, l)
+			}
+		}
+
+		val, err := loader(refreshCtx)
+		if err != nil {
+			logger.Warn("后台刷新缓存失败", zap.String("key", key), zap.Error(err))
+			return
+		}
+		m.store(refreshCtx, key, val, ttl, o)
+	}()
+}
+
+// waitForValue 等待其他实例写入缓存
+func (m *Manager) waitForValue(ctx context.Context, key string, wait time.Duration) (string, bool) {
+	ticker := time.NewTicker(loadLockPollInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-timeout.C:
+			return "", false
+		case <-ticker.C:
+			if val, err := m.Get(ctx, key); err == nil {
+				return val, true
+			}
+		}
+	}
+}
+
+// acquireLoadLock 尝试获取跨实例加载锁
+// 未启用 Redis 时返回 nil，表示不使用跨实例锁，进程内的并发加载已由 singleflight 合并
+func (m *Manager) acquireLoadLock(ctx context.Context, key string, ttl time.Duration) (Locker, bool, error) {
+	redisRepo, ok := m.stores["redis"].(*RedisRepository)
+	if !ok {
+		return nil, false, nil
+	}
+
+	l := redisRepo.Lock(loadLockNamePrefix+key, ttl)
+	acquired, err := l.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	return l, acquired, nil
+}
+
+// releaseLoadLock 释放加载锁，锁已过期并被其他实例获取时不会误删
+func (m *Manager) releaseLoadLock(ctx context.Context, key string, l Locker) {
+	if err := l.Release(ctx); err != nil && !errors.Is(err, ErrLockNotHeld) {
+		logger.Warn("释放缓存加载锁失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// GetOrLoad 读取缓存并反序列化为 T，未命中时调用 loader 加载并序列化写入缓存，行为与 Remember 相同
+// 缓存系统未初始化时直接调用 loader
+func GetOrLoad[T any](ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (T, error), opts ...RememberOption) (T, error) {
+	var result T
+	if err := ensureInitialized(); err != nil {
+		return loader(ctx)
+	}
+
+	data, err := globalStore.Remember(ctx, key, ttl, func(ctx context.Context) (string, error) {
+		val, err := loader(ctx)
+		if err != nil {
+			return "", err
+		}
+		return SerializeObject(val)
+	}, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	if err := DeserializeObject(data, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+)
+
+// initLocalCache 使用本地缓存初始化全局缓存
+func initLocalCache(t *testing.T) {
+	t.Helper()
+	cfg := &config.Config{
+		LocalCache: config.LocalCache{
+			MaxCost: 1 << 20,
+			MaxKeys: 1e4,
+		},
+	}
+	if err := Init(cfg); err != nil {
+		t.Fatalf("初始化缓存失败: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+}
+
+func TestRememberCollapsesConcurrentMisses(t *testing.T) {
+	initLocalCache(t)
+	ctx := context.Background()
+
+	var calls int32
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := Remember(ctx, "remember:hot", time.Minute, loader)
+			if err != nil || val != "value" {
+				t.Errorf("Remember = %q, %v", val, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("loader 调用次数 = %d, 期望 1", calls)
+	}
+
+	// 已缓存时不再调用 loader
+	if _, err := Remember(ctx, "remember:hot", time.Minute, loader); err != nil {
+		t.Fatalf("Remember失败: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("命中缓存后 loader 调用次数 = %d, 期望 1", calls)
+	}
+}
+
+func TestRememberDoesNotCacheErrors(t *testing.T) {
+	initLocalCache(t)
+	ctx := context.Background()
+
+	loadErr := errors.New("数据库不可用")
+	if _, err := Remember(ctx, "remember:error", time.Minute, func(ctx context.Context) (string, error) {
+		return "", loadErr
+	}); !errors.Is(err, loadErr) {
+		t.Fatalf("Remember 错误 = %v, 期望 %v", err, loadErr)
+	}
+
+	if exists, _ := Has(ctx, "remember:error"); exists {
+		t.Error("加载失败时不应写入缓存")
+	}
+}
+
+func TestRememberStaleWhileRevalidate(t *testing.T) {
+	initLocalCache(t)
+	ctx := context.Background()
+
+	var version int32
+	loader := func(ctx context.Context) (string, error) {
+		if atomic.AddInt32(&version, 1) == 1 {
+			return "v1", nil
+		}
+		return "v2", nil
+	}
+
+	ttl := 100 * time.Millisecond
+	if val, _ := Remember(ctx, "remember:stale", ttl, loader, WithStale(time.Minute)); val != "v1" {
+		t.Fatalf("首次加载 = %q, 期望 v1", val)
+	}
+
+	// 超过新鲜期后立即返回旧值，后台刷新完成后返回新值
+	time.Sleep(2 * ttl)
+	if val, _ := Remember(ctx, "remember:stale", ttl, loader, WithStale(time.Minute)); val != "v1" {
+		t.Fatalf("过期后 = %q, 期望旧值 v1", val)
+	}
+	waitFor(t, func() bool {
+		val, _ := Get(ctx, "remember:stale")
+		return val == "v2"
+	})
+}
+
+func TestRememberWithLockWithoutRedis(t *testing.T) {
+	initLocalCache(t)
+
+	// 未启用 Redis 时加载锁退化为进程内合并
+	val, err := Remember(context.Background(), "remember:lock", time.Minute, func(ctx context.Context) (string, error) {
+		return "value", nil
+	}, WithLock())
+	if err != nil || val != "value" {
+		t.Fatalf("Remember = %q, %v", val, err)
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	initLocalCache(t)
+	ctx := context.Background()
+
+	type profile struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var calls int
+	loader := func(ctx context.Context) (*profile, error) {
+		calls++
+		return &profile{ID: 1, Name: "张三"}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		p, err := GetOrLoad(ctx, "profile:1", time.Minute, loader)
+		if err != nil {
+			t.Fatalf("GetOrLoad失败: %v", err)
+		}
+		if p == nil || p.ID != 1 || p.Name != "张三" {
+			t.Fatalf("GetOrLoad = %+v", p)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader 调用次数 = %d, 期望 1", calls)
+	}
+}
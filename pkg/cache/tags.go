@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// TaggedCache 带标签的缓存，写入时记录键与标签的关联，Flush 只删除带有这些标签的键
+// 读取与普通缓存相同，键不会因为带标签而改变
+type TaggedCache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Put(ctx context.Context, key string, value string, ttl time.Duration) error
+	GetObject(ctx context.Context, key string, obj interface{}) error
+	PutObject(ctx context.Context, key string, obj interface{}, ttl time.Duration) error
+	Flush(ctx context.Context) error
+}
+
+// tagStore 支持标签的缓存存储
+type tagStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	putTagged(ctx context.Context, tags []string, key string, value string, ttl time.Duration) error
+	flushTags(ctx context.Context, tags []string) error
+}
+
+// taggedCache 各存储共用的 TaggedCache 实现
+type taggedCache struct {
+	store tagStore
+	tags  []string
+}
+
+func newTaggedCache(store tagStore, tags []string) *taggedCache {
+	return &taggedCache{store: store, tags: tags}
+}
+
+func (t *taggedCache) Get(ctx context.Context, key string) (string, error) {
+	return t.store.Get(ctx, key)
+}
+
+func (t *taggedCache) Put(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return t.store.putTagged(ctx, t.tags, key, value, ttl)
+}
+
+func (t *taggedCache) GetObject(ctx context.Context, key string, obj interface{}) error {
+	data, err := t.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return DeserializeObject(data, obj)
+}
+
+func (t *taggedCache) PutObject(ctx context.Context, key string, obj interface{}, ttl time.Duration) error {
+	data, err := SerializeObject(obj)
+	if err != nil {
+		return err
+	}
+	return t.Put(ctx, key, data, ttl)
+}
+
+// Flush 删除带有任一标签的键
+func (t *taggedCache) Flush(ctx context.Context) error {
+	return t.store.flushTags(ctx, t.tags)
+}
+
+// uninitializedStore 缓存系统未初始化时 Tags 与 Lock 使用的存储，所有操作返回错误
+type uninitializedStore struct{}
+
+func (uninitializedStore) Get(ctx context.Context, key string) (string, error) {
+	return "", ensureInitialized()
+}
+
+func (uninitializedStore) putTagged(ctx context.Context, tags []string, key string, value string, ttl time.Duration) error {
+	return ensureInitialized()
+}
+
+func (uninitializedStore) flushTags(ctx context.Context, tags []string) error {
+	return ensureInitialized()
+}
+
+func (uninitializedStore) acquireLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	return false, ensureInitialized()
+}
+
+func (uninitializedStore) releaseLock(ctx context.Context, name string, owner string) (bool, error) {
+	return false, ensureInitialized()
+}
+
+func (uninitializedStore) refreshLock(ctx context.Context, name string, owner string, ttl time.Duration) (bool, error) {
+	return false, ensureInitialized()
+}
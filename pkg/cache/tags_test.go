@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+)
+
+// testTags 验证按标签清除只删除带有该标签的键
+func testTags(t *testing.T, repo Repository) {
+	t.Helper()
+	ctx := context.Background()
+
+	lists := repo.Tags("news:list")
+	if err := lists.Put(ctx, "news:list:1", "page1", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+	if err := lists.PutObject(ctx, "news:list:2", []string{"a", "b"}, 0); err != nil {
+		t.Fatalf("PutObject失败: %v", err)
+	}
+	if err := repo.Tags("news:list", "news:detail").Put(ctx, "news:detail:1", "detail", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+	if err := repo.Put(ctx, "user:1", "unrelated", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+
+	// 带标签的键可以直接读取
+	if val, err := repo.Get(ctx, "news:list:1"); err != nil || val != "page1" {
+		t.Fatalf("Get = %q, %v, 期望 page1", val, err)
+	}
+	var page []string
+	if err := lists.GetObject(ctx, "news:list:2", &page); err != nil || len(page) != 2 {
+		t.Fatalf("GetObject = %v, %v", page, err)
+	}
+
+	if err := repo.Tags("news:list").Flush(ctx); err != nil {
+		t.Fatalf("Flush失败: %v", err)
+	}
+
+	for _, key := range []string{"news:list:1", "news:list:2", "news:detail:1"} {
+		if exists, _ := repo.Has(ctx, key); exists {
+			t.Errorf("%s 应被清除", key)
+		}
+	}
+	if val, err := repo.Get(ctx, "user:1"); err != nil || val != "unrelated" {
+		t.Errorf("无关的键不应被清除: %q, %v", val, err)
+	}
+
+	// 清除后可以重新写入
+	if err := lists.Put(ctx, "news:list:1", "page1-v2", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+	if val, _ := lists.Get(ctx, "news:list:1"); val != "page1-v2" {
+		t.Errorf("重新写入后 = %q, 期望 page1-v2", val)
+	}
+}
+
+func TestLocalTags(t *testing.T) {
+	repo, err := NewLocalRepository(&config.Config{
+		LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+	})
+	if err != nil {
+		t.Fatalf("创建本地缓存失败: %v", err)
+	}
+	defer repo.Close()
+
+	testTags(t, repo)
+}
+
+func TestRedisTags(t *testing.T) {
+	cfg := newTestRedisConfig(t)
+	repo, err := NewRedisRepository(cfg)
+	if err != nil {
+		t.Fatalf("连接Redis失败: %v", err)
+	}
+	defer repo.Close()
+
+	testTags(t, repo)
+}
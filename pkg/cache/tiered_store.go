@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mygoframe/pkg/config"
+	"mygoframe/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 默认的一级缓存有效期与失效通知频道
+const (
+	defaultL1TTL             = time.Minute
+	defaultInvalidateChannel = "cache:invalidate"
+)
+
+// 失效通知的操作类型
+const (
+	invalidateForget = "forget"
+	invalidateFlush  = "flush"
+)
+
+// invalidation 通过 Redis 发布订阅广播的失效通知
+type invalidation struct {
+	Origin string   `json:"origin"` // 发出通知的实例，实例忽略自己发出的通知
+	Op     string   `json:"op"`
+	Key    string   `json:"key,omitempty"`
+	Keys   []string `json:"keys,omitempty"` // 按标签清除时一次通知多个键
+}
+
+// TieredRepository 两级缓存：本地缓存（L1）+ Redis（L2）
+// 读取先查 L1，未命中时读取 L2 并回填 L1；写入同时写两级。
+// 写入、删除和清空通过 Redis 发布订阅通知其他实例丢弃 L1 中的键。
+// 订阅断开期间可能丢失通知，L1 的有效期限定了数据不一致的最长时间，因此应短于 L2
+type TieredRepository struct {
+	l1      *LocalRepository
+	l2      *RedisRepository
+	l1TTL   time.Duration
+	channel string
+	origin  string
+	pubsub  *redis.PubSub
+	done    chan struct{}
+}
+
+// NewTieredRepository 创建两级缓存并订阅失效通知，l2 的生命周期由调用方管理
+func NewTieredRepository(cfg *config.Config, l2 *RedisRepository) (*TieredRepository, error) {
+	l1, err := NewLocalRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l1TTL := time.Duration(cfg.TieredCache.L1TTL) * time.Second
+	if l1TTL <= 0 {
+		l1TTL = defaultL1TTL
+	}
+	channel := cfg.TieredCache.Channel
+	if channel == "" {
+		channel = defaultInvalidateChannel
+	}
+
+	t := &TieredRepository{
+		l1:      l1,
+		l2:      l2,
+		l1TTL:   l1TTL,
+		channel: l2.prefix + channel,
+		origin:  uuid.New().String(),
+		done:    make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 等待订阅确认后再返回，保证之后发布的通知都能收到
+	t.pubsub = l2.client.Subscribe(ctx, t.channel)
+	if _, err := t.pubsub.Receive(ctx); err != nil {
+		_ = t.pubsub.Close()
+		_ = l1.Close()
+		return nil, fmt.Errorf("订阅缓存失效通知失败: %w", err)
+	}
+	go t.listen()
+
+	return t, nil
+}
+
+func (t *TieredRepository) Get(ctx context.Context, key string) (string, error) {
+	if val, err := t.l1.Get(ctx, key); err == nil {
+		return val, nil
+	}
+
+	// 同时读取值和剩余有效期，回填 L1 时不超过 L2 的剩余有效期
+	fullKey := t.l2.prefix + key
+	pipe := t.l2.client.Pipeline()
+	getCmd := pipe.Get(ctx, fullKey)
+	ttlCmd := pipe.PTTL(ctx, fullKey)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	val, err := getCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	if err != nil {
+		return "", err
+	}
+
+	t.l1.fill(key, val, t.localTTL(ttlCmd.Val()))
+	return val, nil
+}
+
+func (t *TieredRepository) Put(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := t.l2.Put(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	t.publish(ctx, invalidation{Op: invalidateForget, Key: key})
+	return t.l1.Put(ctx, key, value, t.localTTL(ttl))
+}
+
+func (t *TieredRepository) Forget(ctx context.Context, key string) error {
+	_ = t.l1.Forget(ctx, key)
+	if err := t.l2.Forget(ctx, key); err != nil {
+		return err
+	}
+	t.publish(ctx, invalidation{Op: invalidateForget, Key: key})
+	return nil
+}
+
+func (t *TieredRepository) Has(ctx context.Context, key string) (bool, error) {
+	if found, _ := t.l1.Has(ctx, key); found {
+		return true, nil
+	}
+	return t.l2.Has(ctx, key)
+}
+
+func (t *TieredRepository) Flush(ctx context.Context) error {
+	_ = t.l1.Flush(ctx)
+	if err := t.l2.Flush(ctx); err != nil {
+		return err
+	}
+	t.publish(ctx, invalidation{Op: invalidateFlush})
+	return nil
+}
+
+// Increment 在 L2 中原子地增加，并使各实例 L1 中的旧值失效
+func (t *TieredRepository) Increment(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	n, err := t.l2.Increment(ctx, key, by, ttl)
+	if err != nil {
+		return 0, err
+	}
+	t.invalidate(ctx, key)
+	return n, nil
+}
+
+func (t *TieredRepository) Decrement(ctx context.Context, key string, by int64, ttl time.Duration) (int64, error) {
+	return t.Increment(ctx, key, -by, ttl)
+}
+
+// Add 以 L2 的结果为准
+func (t *TieredRepository) Add(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	added, err := t.l2.Add(ctx, key, value, ttl)
+	if err != nil || !added {
+		return added, err
+	}
+	t.invalidate(ctx, key)
+	return true, nil
+}
+
+// Pull 以 L2 的结果为准，多个实例同时读取时只有一个能取到值
+func (t *TieredRepository) Pull(ctx context.Context, key string) (string, error) {
+	val, err := t.l2.Pull(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	t.invalidate(ctx, key)
+	return val, nil
+}
+
+func (t *TieredRepository) Forever(ctx context.Context, key string, value string) error {
+	return t.Put(ctx, key, value, 0)
+}
+
+func (t *TieredRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return t.l2.TTL(ctx, key)
+}
+
+// invalidate 丢弃本实例和其他实例 L1 中的键
+func (t *TieredRepository) invalidate(ctx context.Context, key string) {
+	_ = t.l1.Forget(ctx, key)
+	t.publish(ctx, invalidation{Op: invalidateForget, Key: key})
+}
+
+// Tags 返回带标签的缓存，标签索引保存在 Redis 中，按标签清除时通知所有实例丢弃 L1 中对应的键
+func (t *TieredRepository) Tags(names ...string) TaggedCache {
+	return newTaggedCache(t, names)
+}
+
+func (t *TieredRepository) putTagged(ctx context.Context, tags []string, key string, value string, ttl time.Duration) error {
+	if err := t.l2.putTagged(ctx, tags, key, value, ttl); err != nil {
+		return err
+	}
+	t.publish(ctx, invalidation{Op: invalidateForget, Key: key})
+	return t.l1.Put(ctx, key, value, t.localTTL(ttl))
+}
+
+func (t *TieredRepository) flushTags(ctx context.Context, tags []string) error {
+	keys, err := t.l2.flushTaggedKeys(ctx, tags)
+	for _, key := range keys {
+		_ = t.l1.Forget(ctx, key)
+	}
+	// 分批通知，避免单条消息过大
+	for start := 0; start < len(keys); start += tagFlushBatchSize {
+		end := min(start+tagFlushBatchSize, len(keys))
+		t.publish(ctx, invalidation{Op: invalidateForget, Keys: keys[start:end]})
+	}
+	return err
+}
+
+// Lock 返回 L2 上的分布式锁
+func (t *TieredRepository) Lock(name string, ttl time.Duration) Locker {
+	return t.l2.Lock(name, ttl)
+}
+
+// Close 停止接收失效通知并关闭 L1，L2 由 Manager 关闭
+func (t *TieredRepository) Close() error {
+	close(t.done)
+	err := t.pubsub.Close()
+	_ = t.l1.Close()
+	return err
+}
+
+// Client 返回 L2 的 Redis 客户端
+func (t *TieredRepository) Client() interface{} {
+	return t.l2.Client()
+}
+
+// localTTL 计算 L1 的有效期：取 L2 有效期与配置的 L1 最长有效期中的较小值，L2 永不过期时使用 L1 最长有效期
+func (t *TieredRepository) localTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < t.l1TTL {
+		return ttl
+	}
+	return t.l1TTL
+}
+
+// publish 广播失效通知，发送失败时其他实例的 L1 在有效期后自然过期
+func (t *TieredRepository) publish(ctx context.Context, msg invalidation) {
+	msg.Origin = t.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if err := t.l2.client.Publish(ctx, t.channel, data).Err(); err != nil {
+		logger.Warn("发布缓存失效通知失败", zap.String("op", msg.Op), zap.String("key", msg.Key), zap.Error(err))
+	}
+}
+
+// listen 接收其他实例的失效通知并丢弃 L1 中对应的键，连接断开后由客户端自动重新订阅
+func (t *TieredRepository) listen() {
+	ch := t.pubsub.Channel()
+	for {
+		select {
+		case <-t.done:
+			return
+		case message, ok := <-ch:
+			if !ok {
+				return
+			}
+			t.apply(message.Payload)
+		}
+	}
+}
+
+// apply 处理一条失效通知
+func (t *TieredRepository) apply(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		logger.Warn("解析缓存失效通知失败", zap.Error(err))
+		return
+	}
+	if msg.Origin == t.origin {
+		return
+	}
+
+	ctx := context.Background()
+	switch msg.Op {
+	case invalidateForget:
+		if msg.Key != "" {
+			_ = t.l1.Forget(ctx, msg.Key)
+		}
+		for _, key := range msg.Keys {
+			_ = t.l1.Forget(ctx, key)
+		}
+	case invalidateFlush:
+		_ = t.l1.Flush(ctx)
+	}
+}
@@ -0,0 +1,187 @@
+package cache
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestRedisConfig 返回测试用的 Redis 配置
+// 设置 REDIS_ADDR 环境变量时使用该地址的 Redis，无法连接时跳过测试；否则启动内嵌的 miniredis
+func newTestRedisConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	var host, port string
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		var err error
+		if host, port, err = net.SplitHostPort(addr); err != nil {
+			t.Fatalf("REDIS_ADDR 格式错误: %v", err)
+		}
+	} else {
+		server := newTestMiniredis(t)
+		host, port = server.Host(), server.Port()
+	}
+
+	cfg := &config.Config{
+		Redis: config.Redis{
+			Enabled: true,
+			Host:    host,
+			Port:    port,
+			DB:      15,
+			Prefix:  "cache_test:" + time.Now().Format("150405.000000") + ":",
+		},
+		LocalCache: config.LocalCache{
+			MaxCost: 1 << 20,
+			MaxKeys: 1e4,
+		},
+		TieredCache: config.TieredCache{
+			Enabled: true,
+			L1TTL:   60,
+		},
+	}
+
+	redisRepo, err := NewRedisRepository(cfg)
+	if err != nil {
+		t.Skipf("Redis 不可用，跳过测试: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = redisRepo.Flush(context.Background())
+		_ = redisRepo.Close()
+	})
+
+	return cfg
+}
+
+// newTestMiniredis 启动内嵌的 miniredis，测试结束时关闭
+// miniredis 的键不会随真实时间过期，这里按真实时间推进其时钟，使依赖过期的测试与真实 Redis 行为一致
+func newTestMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				server.FastForward(now.Sub(last))
+				last = now
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+	})
+	return server
+}
+
+func TestTieredLocalTTL(t *testing.T) {
+	tiered := &TieredRepository{l1TTL: time.Minute}
+
+	tests := []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{0, time.Minute},  // L2 永不过期
+		{-1, time.Minute}, // PTTL 返回 -1 表示没有过期时间
+		{10 * time.Second, 10 * time.Second},
+		{time.Hour, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tiered.localTTL(tt.ttl); got != tt.want {
+			t.Errorf("localTTL(%v) = %v, 期望 %v", tt.ttl, got, tt.want)
+		}
+	}
+}
+
+func TestTieredInvalidation(t *testing.T) {
+	cfg := newTestRedisConfig(t)
+	ctx := context.Background()
+
+	// 模拟两个实例：各自拥有 L1，共享同一个 Redis
+	newInstance := func() *TieredRepository {
+		redisRepo, err := NewRedisRepository(cfg)
+		if err != nil {
+			t.Fatalf("连接Redis失败: %v", err)
+		}
+		tiered, err := NewTieredRepository(cfg, redisRepo)
+		if err != nil {
+			t.Fatalf("创建两级缓存失败: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = tiered.Close()
+			_ = redisRepo.Close()
+		})
+		return tiered
+	}
+	a, b := newInstance(), newInstance()
+
+	if err := a.Put(ctx, "user:1", "v1", time.Minute); err != nil {
+		t.Fatalf("Put失败: %v", err)
+	}
+	// b 从 L2 读取并回填自己的 L1
+	if val, err := b.Get(ctx, "user:1"); err != nil || val != "v1" {
+		t.Fatalf("Get = %q, %v, 期望 v1", val, err)
+	}
+	// 回填不等待写入生效
+	waitFor(t, func() bool {
+		val, err := b.l1.Get(ctx, "user:1")
+		return err == nil && val == "v1"
+	})
+
+	// a 删除后 b 的 L1 应收到通知丢弃该键
+	if err := a.Forget(ctx, "user:1"); err != nil {
+		t.Fatalf("Forget失败: %v", err)
+	}
+	waitFor(t, func() bool {
+		found, _ := b.l1.Has(ctx, "user:1")
+		return !found
+	})
+	if _, err := b.Get(ctx, "user:1"); err == nil {
+		t.Error("删除后不应读取到旧值")
+	}
+
+	// a 更新值后 b 不应继续读取 L1 中的旧值
+	_ = a.Put(ctx, "user:2", "old", time.Minute)
+	_, _ = b.Get(ctx, "user:2")
+	_ = a.Put(ctx, "user:2", "new", time.Minute)
+	waitFor(t, func() bool {
+		val, _ := b.Get(ctx, "user:2")
+		return val == "new"
+	})
+
+	// 清空同样通知所有实例
+	_, _ = b.Get(ctx, "user:2")
+	if err := a.Flush(ctx); err != nil {
+		t.Fatalf("Flush失败: %v", err)
+	}
+	waitFor(t, func() bool {
+		found, _ := b.l1.Has(ctx, "user:2")
+		return !found
+	})
+}
+
+// waitFor 等待条件成立，失效通知是异步送达的
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待条件成立超时")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
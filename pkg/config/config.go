@@ -11,21 +11,33 @@ import (
 )
 
 type Config struct {
-	System     System     `mapstructure:"system"`
-	MySQL      Database   `mapstructure:"mysql"`
-	Postgres   Database   `mapstructure:"pgsql"`
-	SQLite     Database   `mapstructure:"sqlite"`
-	Zap        Zap        `mapstructure:"zap"`
-	JWT        JWT        `mapstructure:"jwt"`
-	Redis      Redis      `mapstructure:"redis"`       // 新增 Redis 配置
-	LocalCache LocalCache `mapstructure:"local-cache"` // 本地缓存配置
-	Queue      Queue      `mapstructure:"queue"`       // 队列配置
+	System      System      `mapstructure:"system"`
+	MySQL       Database    `mapstructure:"mysql"`
+	Postgres    Database    `mapstructure:"pgsql"`
+	SQLite      Database    `mapstructure:"sqlite"`
+	Zap         Zap         `mapstructure:"zap"`
+	JWT         JWT         `mapstructure:"jwt"`
+	Redis       Redis       `mapstructure:"redis"`        // 新增 Redis 配置
+	LocalCache  LocalCache  `mapstructure:"local-cache"`  // 本地缓存配置
+	TieredCache TieredCache `mapstructure:"tiered-cache"` // 两级缓存配置
+	Queue       Queue       `mapstructure:"queue"`        // 队列配置
+	Authz       Authz       `mapstructure:"authz"`        // 授权策略配置
+	MFA         MFA         `mapstructure:"mfa"`          // 两步验证配置
+	Login       Login       `mapstructure:"login"`        // 登录配置
+	Register    Register    `mapstructure:"register"`     // 注册配置
+	Password    Password    `mapstructure:"password"`     // 密码哈希与强度策略配置
+	VerifyCode  VerifyCode  `mapstructure:"verify-code"`  // 验证码防滥用配置
+	Mail        Mail        `mapstructure:"mail"`         // 邮件发送配置
+	SMS         SMS         `mapstructure:"sms"`          // 短信发送配置
+	Storage     Storage     `mapstructure:"storage"`      // 文件存储配置
+	Avatar      Avatar      `mapstructure:"avatar"`       // 头像上传配置
 }
 
 type System struct {
-	DbType             string `mapstructure:"db-type"`
-	Addr               int    `mapstructure:"addr"`
-	DisableAutoMigrate bool   `mapstructure:"disable-auto-migrate"`
+	DbType             string   `mapstructure:"db-type"`
+	Addr               int      `mapstructure:"addr"`
+	DisableAutoMigrate bool     `mapstructure:"disable-auto-migrate"`
+	TrustedProxies     []string `mapstructure:"trusted-proxies"` // 可信反向代理的 IP 或 CIDR，只信任这些代理传入的 X-Forwarded-For；为空时直接使用连接地址
 }
 
 type Database struct {
@@ -75,6 +87,25 @@ type JWT struct {
 	Issuer             string `mapstructure:"issuer"`
 	PrivateKeyPath     string `mapstructure:"private-key-path"`
 	PublicKeyPath      string `mapstructure:"public-key-path"`
+	// 密钥环配置，配置后优先于 private-key-path/public-key-path
+	ActiveKeyID string   `mapstructure:"active-key-id"` // 当前用于签名的密钥 kid
+	Keys        []JWTKey `mapstructure:"keys"`
+	OIDC        OIDC     `mapstructure:"oidc"` // 外部身份提供方配置
+}
+
+// OIDC 外部 OIDC 身份提供方配置（如 Casdoor、Keycloak）
+type OIDC struct {
+	Enabled         bool   `mapstructure:"enabled"`          // 是否接受外部身份提供方签发的令牌
+	Issuer          string `mapstructure:"issuer"`           // 身份提供方 issuer，用于发现 JWKS 并校验 iss
+	Audience        string `mapstructure:"audience"`         // 期望的 aud（通常为 client id），启用时必填
+	RefreshInterval int    `mapstructure:"refresh-interval"` // JWKS 刷新间隔（分钟）
+}
+
+// JWTKey 密钥环中的一组密钥
+type JWTKey struct {
+	Kid            string `mapstructure:"kid"`
+	PrivateKeyPath string `mapstructure:"private-key-path"` // 已退役的密钥可不配置私钥，仅用于验证
+	PublicKeyPath  string `mapstructure:"public-key-path"`
 }
 
 // Redis Redis配置
@@ -104,6 +135,13 @@ type LocalCache struct {
 	MaxKeys int64 `mapstructure:"max-keys"` // 本地缓存最大key数量
 }
 
+// TieredCache 两级缓存配置，启用 Redis 时以本地缓存作为一级缓存、Redis 作为二级缓存
+type TieredCache struct {
+	Enabled bool   `mapstructure:"enabled"` // 是否启用两级缓存
+	L1TTL   int    `mapstructure:"l1-ttl"`  // 一级缓存最长有效期(秒)，不超过二级缓存的剩余有效期
+	Channel string `mapstructure:"channel"` // 失效通知的 Redis 发布订阅频道，自动加上 Redis 缓存前缀
+}
+
 // Queue 队列配置
 type Queue struct {
 	Enabled     bool           `mapstructure:"enabled"`     // 是否启用队列服务
@@ -114,6 +152,125 @@ type Queue struct {
 	Retention   int            `mapstructure:"retention"`   // 任务保留时间（秒）
 }
 
+// MFA 两步验证配置
+type MFA struct {
+	Issuer        string `mapstructure:"issuer"`         // 身份验证器中显示的服务名称
+	EncryptionKey string `mapstructure:"encryption-key"` // TOTP 密钥的加密密钥，Base64 编码的 32 字节，可通过环境变量 MFA_ENCRYPTION_KEY 提供
+}
+
+// Login 登录配置：防暴力破解按账号和 IP 分别统计失败次数，以及验证码登录的自动注册
+type Login struct {
+	MaxAccountFailures int  `mapstructure:"max-account-failures"` // 同一账号连续失败多少次后锁定，0 表示不限制
+	MaxIPFailures      int  `mapstructure:"max-ip-failures"`      // 同一 IP 连续失败多少次后锁定，0 表示不限制
+	MaxMFAFailures     int  `mapstructure:"max-mfa-failures"`     // 同一用户两步验证连续失败多少次后锁定，默认 5，不能关闭
+	FailureWindow      int  `mapstructure:"failure-window"`       // 失败计数的统计窗口（分钟）
+	LockoutDuration    int  `mapstructure:"lockout-duration"`     // 锁定时长（分钟）
+	DelayAfter         int  `mapstructure:"delay-after"`          // 失败多少次后开始延迟响应
+	BaseDelay          int  `mapstructure:"base-delay"`           // 首次延迟时长（毫秒），之后每次失败翻倍
+	MaxDelay           int  `mapstructure:"max-delay"`            // 最大延迟时长（毫秒）
+	AutoRegister       bool `mapstructure:"auto-register"`        // 验证码登录时账号不存在是否自动注册
+}
+
+// Register 注册配置
+type Register struct {
+	EmailVerification   bool   `mapstructure:"email-verification"`   // 注册后是否需要验证邮箱才能登录
+	VerifyURL           string `mapstructure:"verify-url"`           // 邮件中的验证链接，令牌以 token 参数附加
+	VerifyTokenTTL      int    `mapstructure:"verify-token-ttl"`     // 验证链接有效期（小时）
+	UnverifiedRetention int    `mapstructure:"unverified-retention"` // 未验证账号保留时长（小时），超过后由定时任务删除，0 表示不清理
+}
+
+// Password 密码哈希配置，修改算法或提高参数后，旧哈希会在用户下次登录时自动升级
+type Password struct {
+	Algorithm  string         `mapstructure:"algorithm"`   // 新密码使用的算法：argon2id、bcrypt
+	Argon2     Argon2         `mapstructure:"argon2"`      // argon2id 参数
+	BcryptCost int            `mapstructure:"bcrypt-cost"` // bcrypt 代价因子
+	Policy     PasswordPolicy `mapstructure:"policy"`      // 密码强度策略
+}
+
+// Argon2 argon2id 参数
+type Argon2 struct {
+	Memory      uint32 `mapstructure:"memory"`      // 内存开销（KiB）
+	Iterations  uint32 `mapstructure:"iterations"`  // 迭代次数
+	Parallelism uint8  `mapstructure:"parallelism"` // 并行度
+	SaltLength  uint32 `mapstructure:"salt-length"` // 盐值长度（字节）
+	KeyLength   uint32 `mapstructure:"key-length"`  // 哈希长度（字节）
+}
+
+// PasswordPolicy 密码强度策略，在注册、修改和重置密码时检查
+type PasswordPolicy struct {
+	MinLength     int    `mapstructure:"min-length"`     // 最小长度
+	RequireUpper  bool   `mapstructure:"require-upper"`  // 必须包含大写字母
+	RequireLower  bool   `mapstructure:"require-lower"`  // 必须包含小写字母
+	RequireDigit  bool   `mapstructure:"require-digit"`  // 必须包含数字
+	RequireSymbol bool   `mapstructure:"require-symbol"` // 必须包含特殊字符
+	BreachedList  string `mapstructure:"breached-list"`  // 泄露密码列表文件，每行一个密码，为空时不检查
+}
+
+// VerifyCode 验证码防滥用配置，短信与邮箱验证码共用
+type VerifyCode struct {
+	TTL          int `mapstructure:"ttl"`            // 验证码有效期（秒）
+	Cooldown     int `mapstructure:"cooldown"`       // 同一手机号/邮箱两次发送的最小间隔（秒）
+	DailyLimit   int `mapstructure:"daily-limit"`    // 同一手机号/邮箱每日发送上限，0 表示不限制
+	IPDailyLimit int `mapstructure:"ip-daily-limit"` // 同一 IP 每日发送上限，0 表示不限制
+	MaxAttempts  int `mapstructure:"max-attempts"`   // 同一验证码允许的最大错误次数，超过后验证码作废
+}
+
+// Mail 邮件发送配置
+type Mail struct {
+	Driver   string `mapstructure:"driver"`    // 发送驱动：smtp、log、memory
+	From     string `mapstructure:"from"`      // 发件人，如 "MyGoFrame <no-reply@example.com>"
+	FilePath string `mapstructure:"file-path"` // log 驱动写入的文件，为空时写入日志
+	SMTP     SMTP   `mapstructure:"smtp"`
+}
+
+// SMTP SMTP 服务器配置
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"` // 为空时不进行认证
+	Password string `mapstructure:"password"`
+	TLS      bool   `mapstructure:"tls"`     // 是否使用隐式 TLS（通常为 465 端口），否则在服务器支持时使用 STARTTLS
+	Timeout  int    `mapstructure:"timeout"` // 连接超时时间（秒）
+}
+
+// SMS 短信发送配置
+type SMS struct {
+	Driver   string     `mapstructure:"driver"`    // 发送驱动：http、log、memory
+	FilePath string     `mapstructure:"file-path"` // log 驱动写入的文件，为空时写入日志
+	Gateway  SMSGateway `mapstructure:"gateway"`
+}
+
+// SMSGateway 通用 HTTP 短信网关配置
+type SMSGateway struct {
+	URL     string `mapstructure:"url"`     // 网关地址，以 JSON 格式 POST {"phone": "...", "content": "..."}
+	Token   string `mapstructure:"token"`   // 以 Authorization: Bearer 方式发送，为空时不发送
+	Timeout int    `mapstructure:"timeout"` // 请求超时时间（秒）
+}
+
+// Storage 文件存储配置
+type Storage struct {
+	Driver string       `mapstructure:"driver"` // 存储驱动：local、memory
+	Local  LocalStorage `mapstructure:"local"`
+}
+
+// LocalStorage 本地文件存储配置
+type LocalStorage struct {
+	Root      string `mapstructure:"root"`       // 存储根目录
+	URLPrefix string `mapstructure:"url-prefix"` // 访问地址前缀，本地驱动会在该路径下提供静态文件访问
+}
+
+// Avatar 头像上传配置
+type Avatar struct {
+	MaxSize      int   `mapstructure:"max-size"`      // 上传文件大小上限（KB）
+	MaxDimension int   `mapstructure:"max-dimension"` // 原图宽高上限（像素），防止解码超大图片耗尽内存
+	Sizes        []int `mapstructure:"sizes"`         // 生成的正方形缩略图边长（像素），第一个作为默认头像
+}
+
+// Authz 授权策略配置
+type Authz struct {
+	PolicyFile string `mapstructure:"policy-file"` // 策略文件路径，修改后自动重新加载
+}
+
 func GetBuildMode() string {
 	mode := "dev"
 	if envMode := os.Getenv("BUILD_MODE"); envMode != "" {
@@ -141,6 +298,9 @@ func GetConfig() *Config {
 	viper.AddConfigPath(".")        // 当前目录查找
 	viper.AddConfigPath("./config") // config目录查找
 
+	// 密钥类配置不提交到仓库，允许部署时通过环境变量提供
+	_ = viper.BindEnv("mfa.encryption-key", "MFA_ENCRYPTION_KEY")
+
 	if err := viper.ReadInConfig(); err != nil {
 		if configName != "config" {
 			log.Printf("读取配置文件 %s.yaml 失败: %v，尝试读取默认配置文件", configName, err)
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"mygoframe/pkg/config"
+)
+
+// HTTPSMSSender 通过通用 HTTP 短信网关发送短信
+// 以 JSON 格式 POST {"phone": "...", "content": "..."}，网关返回 2xx 视为发送成功
+type HTTPSMSSender struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPSMSSender 创建 HTTP 短信网关发送器
+func NewHTTPSMSSender(cfg config.SMSGateway) (*HTTPSMSSender, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("未配置短信网关地址")
+	}
+
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &HTTPSMSSender{
+		url:    cfg.URL,
+		token:  cfg.Token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Send 发送短信
+func (s *HTTPSMSSender) Send(ctx context.Context, msg SMS) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求短信网关失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("短信网关返回错误: %s %s", resp.Status, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package notify
+
+import "context"
+
+// Email 邮件内容
+type Email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"` // 纯文本正文
+}
+
+// SMS 短信内容
+type SMS struct {
+	Phone   string `json:"phone"`
+	Content string `json:"content"`
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg Email) error
+}
+
+// SMSSender 短信发送接口
+type SMSSender interface {
+	Send(ctx context.Context, msg SMS) error
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"mygoframe/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// logWriter 开发环境使用的输出：配置了文件路径时追加写入文件，否则写入日志
+type logWriter struct {
+	path string
+	mu   sync.Mutex
+}
+
+// write 记录一条消息，kv 为成对的字段名与字段值
+func (w *logWriter) write(kind string, kv ...string) error {
+	if w.path == "" {
+		fields := make([]zap.Field, 0, len(kv)/2)
+		for i := 0; i+1 < len(kv); i += 2 {
+			fields = append(fields, zap.String(kv[i], kv[i+1]))
+		}
+		logger.Info("模拟发送"+kind, fields...)
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", time.Now().Format(time.RFC3339), kind)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fmt.Fprintf(&b, " %s=%q", kv[i], kv[i+1])
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("写入%s文件失败: %w", kind, err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, b.String())
+	return err
+}
+
+// LogMailer 将邮件写入文件或日志而不真正发送，用于开发环境
+type LogMailer struct {
+	w *logWriter
+}
+
+// NewLogMailer 创建日志邮件发送器，path 为空时写入日志
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{w: &logWriter{path: path}}
+}
+
+// Send 记录邮件内容
+func (m *LogMailer) Send(ctx context.Context, msg Email) error {
+	return m.w.write("邮件", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+}
+
+// LogSMSSender 将短信写入文件或日志而不真正发送，用于开发环境
+type LogSMSSender struct {
+	w *logWriter
+}
+
+// NewLogSMSSender 创建日志短信发送器，path 为空时写入日志
+func NewLogSMSSender(path string) *LogSMSSender {
+	return &LogSMSSender{w: &logWriter{path: path}}
+}
+
+// Send 记录短信内容
+func (s *LogSMSSender) Send(ctx context.Context, msg SMS) error {
+	return s.w.write("短信", "phone", msg.Phone, "content", msg.Content)
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer 将邮件保存在内存中，用于测试断言
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+// NewMemoryMailer 创建内存邮件发送器
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send 保存邮件
+func (m *MemoryMailer) Send(ctx context.Context, msg Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent 返回已发送的邮件
+func (m *MemoryMailer) Sent() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.sent...)
+}
+
+// Reset 清空已发送的邮件
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
+
+// MemorySMSSender 将短信保存在内存中，用于测试断言
+type MemorySMSSender struct {
+	mu   sync.Mutex
+	sent []SMS
+}
+
+// NewMemorySMSSender 创建内存短信发送器
+func NewMemorySMSSender() *MemorySMSSender {
+	return &MemorySMSSender{}
+}
+
+// Send 保存短信
+func (s *MemorySMSSender) Send(ctx context.Context, msg SMS) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// Sent 返回已发送的短信
+func (s *MemorySMSSender) Sent() []SMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMS(nil), s.sent...)
+}
+
+// Reset 清空已发送的短信
+func (s *MemorySMSSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+}
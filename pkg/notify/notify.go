@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"mygoframe/pkg/config"
+)
+
+// ErrNotInitialized 发送服务未初始化
+var ErrNotInitialized = errors.New("消息发送服务未初始化")
+
+var (
+	defaultMailer    Mailer
+	defaultSMSSender SMSSender
+	mu               sync.RWMutex
+)
+
+// Init 根据配置初始化默认的邮件与短信发送驱动
+func Init(cfg *config.Config) error {
+	mailer, err := NewMailer(cfg.Mail)
+	if err != nil {
+		return err
+	}
+	sender, err := NewSMSSender(cfg.SMS)
+	if err != nil {
+		return err
+	}
+
+	SetMailer(mailer)
+	SetSMSSender(sender)
+	return nil
+}
+
+// NewMailer 根据驱动名称创建邮件发送器
+func NewMailer(cfg config.Mail) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg)
+	case "log", "":
+		return NewLogMailer(cfg.FilePath), nil
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件发送驱动: %s", cfg.Driver)
+	}
+}
+
+// NewSMSSender 根据驱动名称创建短信发送器
+func NewSMSSender(cfg config.SMS) (SMSSender, error) {
+	switch cfg.Driver {
+	case "http":
+		return NewHTTPSMSSender(cfg.Gateway)
+	case "log", "":
+		return NewLogSMSSender(cfg.FilePath), nil
+	case "memory":
+		return NewMemorySMSSender(), nil
+	default:
+		return nil, fmt.Errorf("不支持的短信发送驱动: %s", cfg.Driver)
+	}
+}
+
+// SetMailer 设置默认邮件发送器，便于测试替换
+func SetMailer(mailer Mailer) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultMailer = mailer
+}
+
+// GetMailer 获取默认邮件发送器
+func GetMailer() Mailer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultMailer
+}
+
+// SetSMSSender 设置默认短信发送器，便于测试替换
+func SetSMSSender(sender SMSSender) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultSMSSender = sender
+}
+
+// GetSMSSender 获取默认短信发送器
+func GetSMSSender() SMSSender {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultSMSSender
+}
+
+// SendEmail 使用默认邮件发送器发送邮件
+func SendEmail(ctx context.Context, msg Email) error {
+	mailer := GetMailer()
+	if mailer == nil {
+		return ErrNotInitialized
+	}
+	return mailer.Send(ctx, msg)
+}
+
+// SendSMS 使用默认短信发送器发送短信
+func SendSMS(ctx context.Context, msg SMS) error {
+	sender := GetSMSSender()
+	if sender == nil {
+		return ErrNotInitialized
+	}
+	return sender.Send(ctx, msg)
+}
@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"mygoframe/pkg/config"
+)
+
+// fakeSMTPServer 本地的 SMTP 服务器替身，记录收到的信封和邮件内容
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu   sync.Mutex
+	from string
+	rcpt []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: ln}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var b strings.Builder
+			for {
+				dl, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dl == ".\r\n" {
+					break
+				}
+				b.WriteString(dl)
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := newFakeSMTPServer(t)
+
+	mailer, err := NewSMTPMailer(config.Mail{
+		From: "MyGoFrame <no-reply@example.com>",
+		SMTP: config.SMTP{Host: "127.0.0.1", Port: server.port()},
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+
+	msg := Email{To: "user@example.com", Subject: "验证码", Body: "您的验证码为 123456"}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "no-reply@example.com" {
+		t.Fatalf("unexpected MAIL FROM %q", server.from)
+	}
+	if len(server.rcpt) != 1 || server.rcpt[0] != msg.To {
+		t.Fatalf("unexpected RCPT TO %v", server.rcpt)
+	}
+
+	header, body, _ := strings.Cut(server.data, "\r\n\r\n")
+	var subject string
+	for _, line := range strings.Split(header, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "Subject: "); ok {
+			subject, _ = new(mime.WordDecoder).DecodeHeader(v)
+		}
+	}
+	if subject != msg.Subject {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if err != nil || string(decoded) != msg.Body {
+		t.Fatalf("unexpected body %q (%v)", decoded, err)
+	}
+}
+
+func TestHTTPSMSSenderSend(t *testing.T) {
+	var got SMS
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	sender, err := NewHTTPSMSSender(config.SMSGateway{URL: server.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("NewHTTPSMSSender: %v", err)
+	}
+
+	msg := SMS{Phone: "13800000000", Content: "您的验证码为 123456"}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got != msg {
+		t.Fatalf("gateway received %+v, want %+v", got, msg)
+	}
+	if auth != "Bearer secret" {
+		t.Fatalf("unexpected Authorization header %q", auth)
+	}
+}
+
+func TestHTTPSMSSenderGatewayError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sender, _ := NewHTTPSMSSender(config.SMSGateway{URL: server.URL})
+	err := sender.Send(context.Background(), SMS{Phone: "13800000000", Content: "x"})
+	if err == nil || !strings.Contains(err.Error(), strconv.Itoa(http.StatusTooManyRequests)) {
+		t.Fatalf("expected gateway error, got %v", err)
+	}
+}
+
+func TestLogDriverWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sms.log")
+	sender := NewLogSMSSender(path)
+
+	if err := sender.Send(context.Background(), SMS{Phone: "13800000000", Content: "验证码 654321"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), "13800000000") || !strings.Contains(string(data), "654321") {
+		t.Fatalf("unexpected log content %q", data)
+	}
+}
+
+func TestDriverSelection(t *testing.T) {
+	mailer, err := NewMailer(config.Mail{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("NewMailer: %v", err)
+	}
+	SetMailer(mailer)
+	defer SetMailer(nil)
+
+	if err := SendEmail(context.Background(), Email{To: "a@example.com", Subject: "s", Body: "b"}); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if sent := mailer.(*MemoryMailer).Sent(); len(sent) != 1 || sent[0].To != "a@example.com" {
+		t.Fatalf("unexpected sent mails %+v", sent)
+	}
+
+	if _, err := NewMailer(config.Mail{Driver: "pigeon"}); err == nil {
+		t.Fatal("expected error for unknown mail driver")
+	}
+	if _, err := NewSMSSender(config.SMS{Driver: "http"}); err == nil {
+		t.Fatal("expected error for http driver without gateway url")
+	}
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage 文件存储接口，path 为相对于存储根目录的对象路径，如 "avatars/<id>/256.png"
+type Storage interface {
+	// Put 写入文件，已存在时覆盖
+	Put(ctx context.Context, path string, r io.Reader, contentType string) error
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, path string) error
+	// URL 返回文件的访问地址
+	URL(path string) string
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"mygoframe/pkg/config"
+)
+
+// LocalStorage 将文件保存在本地目录，通过静态文件路由对外提供访问
+type LocalStorage struct {
+	root      string
+	urlPrefix string
+}
+
+// NewLocalStorage 创建本地文件存储，根目录不存在时自动创建
+func NewLocalStorage(cfg config.LocalStorage) (*LocalStorage, error) {
+	root := cfg.Root
+	if root == "" {
+		root = "storage/uploads"
+	}
+	prefix := cfg.URLPrefix
+	if prefix == "" {
+		prefix = "/uploads"
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建存储目录失败: %w", err)
+	}
+	return &LocalStorage{root: root, urlPrefix: prefix}, nil
+}
+
+// Root 本地存储根目录
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+// URLPrefix 访问地址前缀
+func (s *LocalStorage) URLPrefix() string {
+	return s.urlPrefix
+}
+
+// Put 写入文件，先写入临时文件再重命名，避免读取到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, path string, r io.Reader, contentType string) error {
+	cleaned, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(s.root, filepath.FromSlash(cleaned))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("创建存储目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("保存文件失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(ctx context.Context, path string) error {
+	cleaned, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filepath.Join(s.root, filepath.FromSlash(cleaned)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+	return nil
+}
+
+// URL 返回文件的访问地址
+func (s *LocalStorage) URL(path string) string {
+	return joinURL(s.urlPrefix, path)
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryFile 内存存储中保存的文件
+type MemoryFile struct {
+	Data        []byte
+	ContentType string
+}
+
+// MemoryStorage 将文件保存在内存中，用于测试断言
+type MemoryStorage struct {
+	mu        sync.Mutex
+	urlPrefix string
+	files     map[string]MemoryFile
+}
+
+// NewMemoryStorage 创建内存文件存储
+func NewMemoryStorage(urlPrefix string) *MemoryStorage {
+	if urlPrefix == "" {
+		urlPrefix = "/uploads"
+	}
+	return &MemoryStorage{urlPrefix: urlPrefix, files: make(map[string]MemoryFile)}
+}
+
+// Put 保存文件
+func (s *MemoryStorage) Put(ctx context.Context, path string, r io.Reader, contentType string) error {
+	cleaned, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[cleaned] = MemoryFile{Data: data, ContentType: contentType}
+	return nil
+}
+
+// Delete 删除文件
+func (s *MemoryStorage) Delete(ctx context.Context, path string) error {
+	cleaned, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, cleaned)
+	return nil
+}
+
+// URL 返回文件的访问地址
+func (s *MemoryStorage) URL(path string) string {
+	return joinURL(s.urlPrefix, path)
+}
+
+// File 返回已保存的文件
+func (s *MemoryStorage) File(path string) (MemoryFile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.files[path]
+	return f, ok
+}
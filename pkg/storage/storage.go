@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"mygoframe/pkg/config"
+)
+
+var (
+	// ErrNotInitialized 存储服务未初始化
+	ErrNotInitialized = errors.New("文件存储服务未初始化")
+	// ErrInvalidPath 对象路径为空或试图访问存储根目录之外的文件
+	ErrInvalidPath = errors.New("无效的文件路径")
+)
+
+var (
+	defaultStorage Storage
+	mu             sync.RWMutex
+)
+
+// Init 根据配置初始化默认的文件存储驱动
+func Init(cfg *config.Config) error {
+	s, err := New(cfg.Storage)
+	if err != nil {
+		return err
+	}
+	Set(s)
+	return nil
+}
+
+// New 根据驱动名称创建文件存储
+func New(cfg config.Storage) (Storage, error) {
+	switch cfg.Driver {
+	case "local", "":
+		return NewLocalStorage(cfg.Local)
+	case "memory":
+		return NewMemoryStorage(cfg.Local.URLPrefix), nil
+	default:
+		return nil, fmt.Errorf("不支持的文件存储驱动: %s", cfg.Driver)
+	}
+}
+
+// Set 设置默认文件存储，便于测试替换
+func Set(s Storage) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultStorage = s
+}
+
+// Get 获取默认文件存储
+func Get() Storage {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultStorage
+}
+
+// Put 使用默认文件存储写入文件
+func Put(ctx context.Context, path string, r io.Reader, contentType string) error {
+	s := Get()
+	if s == nil {
+		return ErrNotInitialized
+	}
+	return s.Put(ctx, path, r, contentType)
+}
+
+// Delete 使用默认文件存储删除文件
+func Delete(ctx context.Context, path string) error {
+	s := Get()
+	if s == nil {
+		return ErrNotInitialized
+	}
+	return s.Delete(ctx, path)
+}
+
+// URL 返回默认文件存储中文件的访问地址
+func URL(path string) string {
+	s := Get()
+	if s == nil {
+		return ""
+	}
+	return s.URL(path)
+}
+
+// cleanPath 规范化对象路径，拒绝绝对路径和包含 ".." 的路径
+func cleanPath(p string) (string, error) {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, "\\") {
+		return "", ErrInvalidPath
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidPath
+	}
+	return cleaned, nil
+}
+
+// joinURL 拼接访问地址前缀与对象路径
+func joinURL(prefix, p string) string {
+	return strings.TrimRight(prefix, "/") + "/" + p
+}
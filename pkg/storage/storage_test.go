@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mygoframe/pkg/config"
+)
+
+func TestLocalStoragePutAndDelete(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(config.LocalStorage{Root: root, URLPrefix: "/uploads/"})
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := s.Put(ctx, "avatars/u1/256.png", strings.NewReader("first"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	// 再次写入同一路径时覆盖
+	if err := s.Put(ctx, "avatars/u1/256.png", strings.NewReader("second"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "avatars", "u1", "256.png"))
+	if err != nil || string(data) != "second" {
+		t.Fatalf("unexpected file content %q (%v)", data, err)
+	}
+	if url := s.URL("avatars/u1/256.png"); url != "/uploads/avatars/u1/256.png" {
+		t.Fatalf("unexpected url %q", url)
+	}
+
+	if err := s.Delete(ctx, "avatars/u1/256.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete(ctx, "avatars/u1/256.png"); err != nil {
+		t.Fatalf("deleting a missing file should succeed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "avatars", "u1", "256.png")); !os.IsNotExist(err) {
+		t.Fatalf("file should be removed, stat err: %v", err)
+	}
+}
+
+func TestStorageRejectsInvalidPath(t *testing.T) {
+	s, err := NewLocalStorage(config.LocalStorage{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	for _, p := range []string{"", "/etc/passwd", "../secret", "a/../../secret", `a\b`} {
+		if err := s.Put(context.Background(), p, strings.NewReader("x"), ""); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidPath", p, err)
+		}
+	}
+}
+
+func TestDriverSelection(t *testing.T) {
+	s, err := New(config.Storage{Driver: "memory"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	Set(s)
+	defer Set(nil)
+
+	if err := Put(context.Background(), "a/b.txt", strings.NewReader("hello"), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	f, ok := s.(*MemoryStorage).File("a/b.txt")
+	if !ok || string(f.Data) != "hello" || f.ContentType != "text/plain" {
+		t.Fatalf("unexpected stored file %+v", f)
+	}
+	if url := URL("a/b.txt"); url != "/uploads/a/b.txt" {
+		t.Fatalf("unexpected url %q", url)
+	}
+
+	if _, err := New(config.Storage{Driver: "floppy"}); err == nil {
+		t.Fatal("expected error for unknown storage driver")
+	}
+
+	Set(nil)
+	if err := Put(context.Background(), "a", strings.NewReader(""), ""); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("expected ErrNotInitialized, got %v", err)
+	}
+}
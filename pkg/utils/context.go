@@ -0,0 +1,20 @@
+package utils
+
+import "context"
+
+// 请求上下文中的键，由中间件写入 gin.Context，服务层通过 ctx.Value 读取
+const (
+	ContextKeyRequestID = "request_id"
+	ContextKeyClientIP  = "client_ip"
+	ContextKeyUserAgent = "user_agent"
+	ContextKeyUserID    = "user_id"
+)
+
+// ContextString 读取上下文中的字符串值，不存在或类型不符时返回空字符串
+func ContextString(ctx context.Context, key string) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Encryptor 使用 AES-256-GCM 加密需要落库的敏感数据（如 TOTP 密钥）
+type Encryptor struct {
+	aead cipher.AEAD
+}
+
+// NewEncryptor 创建加密器，key 为 Base64 编码的 32 字节密钥
+func NewEncryptor(key string) (*Encryptor, error) {
+	if key == "" {
+		return nil, errors.New("未配置加密密钥")
+	}
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("解析加密密钥失败: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("加密密钥长度必须为 32 字节，实际 %d 字节", len(raw))
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Encryptor{aead: aead}, nil
+}
+
+// Encrypt 加密字符串，输出为 Base64(nonce || 密文)
+func (e *Encryptor) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := e.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密 Encrypt 的输出
+func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("解析密文失败: %w", err)
+	}
+	nonceSize := e.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("密文长度错误")
+	}
+	plaintext, err := e.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("解密失败，密文已损坏或密钥不匹配")
+	}
+	return string(plaintext), nil
+}
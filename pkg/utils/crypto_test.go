@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestEncryptor(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	e, err := NewEncryptor(key)
+	if err != nil {
+		t.Fatalf("创建加密器失败: %v", err)
+	}
+
+	ciphertext, err := e.Encrypt("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("加密失败: %v", err)
+	}
+	if strings.Contains(ciphertext, "JBSWY3DPEHPK3PXP") {
+		t.Fatal("密文不应包含明文")
+	}
+
+	plaintext, err := e.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("解密失败: %v", err)
+	}
+	if plaintext != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("解密结果不匹配: %s", plaintext)
+	}
+
+	other, _ := NewEncryptor(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 32))))
+	if _, err := other.Decrypt(ciphertext); err == nil {
+		t.Error("使用错误的密钥不应解密成功")
+	}
+
+	if _, err := NewEncryptor(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("密钥长度错误时应创建失败")
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // 注册 GIF 解码器
+	_ "image/jpeg" // 注册 JPEG 解码器
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+)
+
+var (
+	// ErrUnsupportedImage 不支持的图片格式
+	ErrUnsupportedImage = errors.New("仅支持 JPEG、PNG、GIF、WebP 格式的图片")
+	// ErrImageTooLarge 图片宽高超过上限
+	ErrImageTooLarge = errors.New("图片尺寸过大")
+)
+
+// allowedImageTypes 允许上传的图片类型（根据文件内容识别，而不是扩展名或请求头）
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// DecodeImage 识别并解码图片，解码前先检查宽高，避免超大图片耗尽内存
+func DecodeImage(data []byte, maxDimension int) (image.Image, error) {
+	if !allowedImageTypes[http.DetectContentType(data)] {
+		return nil, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedImage
+	}
+	if maxDimension > 0 && (cfg.Width > maxDimension || cfg.Height > maxDimension) {
+		return nil, fmt.Errorf("%w: 宽高不能超过 %d 像素", ErrImageTooLarge, maxDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+	return img, nil
+}
+
+// Thumbnail 从图片中心裁剪出最大的正方形并缩放为 size×size
+func Thumbnail(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	crop := image.Rect(x0, y0, x0+side, y0+side)
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// EncodePNG 将图片编码为 PNG，保留透明通道
+func EncodePNG(w io.Writer, img image.Image) error {
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	return encoder.Encode(w, img)
+}
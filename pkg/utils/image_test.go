@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// encodeTestPNG 生成指定尺寸的 PNG，左半部分红色、右半部分蓝色
+func encodeTestPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeImage(t *testing.T) {
+	if _, err := DecodeImage(encodeTestPNG(t, 40, 20), 100); err != nil {
+		t.Fatalf("合法的图片应解码成功: %v", err)
+	}
+	if _, err := DecodeImage(encodeTestPNG(t, 200, 20), 100); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("超过尺寸上限应返回 ErrImageTooLarge, got %v", err)
+	}
+	if _, err := DecodeImage([]byte("<html>not an image</html>"), 100); !errors.Is(err, ErrUnsupportedImage) {
+		t.Errorf("非图片内容应返回 ErrUnsupportedImage, got %v", err)
+	}
+	// 文件头是 PNG 但内容损坏
+	data := encodeTestPNG(t, 10, 10)
+	if _, err := DecodeImage(data[:20], 100); err == nil {
+		t.Error("损坏的图片应返回错误")
+	}
+}
+
+func TestThumbnailCropsCenterSquare(t *testing.T) {
+	img, err := DecodeImage(encodeTestPNG(t, 300, 100), 0)
+	if err != nil {
+		t.Fatalf("DecodeImage: %v", err)
+	}
+
+	thumb := Thumbnail(img, 64)
+	if b := thumb.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("缩略图尺寸应为 64x64, got %v", b)
+	}
+
+	// 从中心裁剪 100x100，左侧为红色、右侧为蓝色
+	if r, _, b, _ := thumb.At(5, 32).RGBA(); r>>8 < 200 || b>>8 > 50 {
+		t.Errorf("左侧像素应为红色, got r=%d b=%d", r>>8, b>>8)
+	}
+	if r, _, b, _ := thumb.At(58, 32).RGBA(); b>>8 < 200 || r>>8 > 50 {
+		t.Errorf("右侧像素应为蓝色, got r=%d b=%d", r>>8, b>>8)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodePNG(&buf, thumb); err != nil {
+		t.Fatalf("EncodePNG: %v", err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatalf("编码结果应为合法 PNG: %v", err)
+	}
+}
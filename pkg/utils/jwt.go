@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"errors"
@@ -14,6 +18,7 @@ import (
 	"mygoframe/pkg/config"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var (
@@ -28,14 +33,47 @@ type UserInfo struct {
 	Avatar      string `json:"avatar"`
 	Email       string `json:"email"`
 	Phone       string `json:"phone"`
+	// Roles 登录时写入的角色名称，角色变更需重新签发令牌才会生效
+	Roles []string `json:"roles,omitempty"`
 }
 
+// 令牌类型，写入 token_type 声明，防止访问令牌与刷新令牌混用
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeMFAPending 密码验证通过、等待两步验证的临时令牌，只能用于完成登录
+	TokenTypeMFAPending = "mfa_pending"
+	// TokenTypeEmailVerify 注册后发送到邮箱的验证令牌，只能用于激活账号
+	TokenTypeEmailVerify = "email_verify"
+)
+
+// mfaTokenTTL 两步验证临时令牌的有效期
+const mfaTokenTTL = 5 * time.Minute
+
+// ErrInvalidTokenType 表示令牌类型与使用场景不符
+var ErrInvalidTokenType = errors.New("令牌类型不匹配")
+
 // Claims 定义了 JWT 的声明，包括自定义的用户信息和标准的注册声明
 type Claims struct {
 	*UserInfo
+	TokenType string `json:"token_type,omitempty"`
+	// Family 令牌族ID，同一次登录及其后续刷新产生的令牌属于同一族
+	Family string `json:"fam,omitempty"`
+	// IssuedAtMs 毫秒精度的签发时间，iat 只精确到秒，吊销时间点之后立即签发的令牌（如修改密码后重新登录）据此与旧令牌区分
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenPair 一次签发的访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+	Family       string
+	// RefreshExpiresAt 刷新令牌的过期时间，也是该令牌族对应会话的过期时间
+	RefreshExpiresAt time.Time
+}
+
 // Signer 定义了 JWT 签名和验证的接口
 type Signer interface {
 	Sign(claims Claims) (string, error)
@@ -45,8 +83,11 @@ type Signer interface {
 
 // JWTUtil 是 JWT 操作的核心结构
 type JWTUtil struct {
-	config config.JWT
-	signer Signer
+	config     config.JWT
+	signer     Signer
+	revocation RevocationStore
+	// external 外部 OIDC 身份提供方的验证器，未启用时为 nil
+	external *oidcVerifier
 }
 
 // GetJWTUtil 使用单例模式获取 JWTUtil 实例
@@ -64,9 +105,20 @@ func GetJWTUtil() (*JWTUtil, error) {
 			return
 		}
 
+		var external *oidcVerifier
+		if cfg.OIDC.Enabled && strings.ToUpper(cfg.SigningMethod) != "OIDC" {
+			external, err = newOIDCVerifier(cfg.OIDC)
+			if err != nil {
+				initErr = fmt.Errorf("创建 OIDC 验证器失败: %w", err)
+				return
+			}
+		}
+
 		jwtUtil = &JWTUtil{
-			config: cfg,
-			signer: signer,
+			config:     cfg,
+			signer:     signer,
+			revocation: NewCacheRevocationStore(),
+			external:   external,
 		}
 	})
 
@@ -84,39 +136,111 @@ func newSigner(cfg config.JWT) (Signer, error) {
 	method := strings.ToUpper(cfg.SigningMethod)
 	switch method {
 	case "RS256", "RS384", "RS512":
-		return newRSASigner(method, cfg.PrivateKeyPath, cfg.PublicKeyPath)
+		return newRSASigner(method, cfg)
+	case "ES256", "ES384", "ES512":
+		return newECDSASigner(method, cfg)
+	case "EDDSA":
+		return newEdDSASigner(cfg)
 	case "HS256", "HS384", "HS512":
 		return newHMACSigner(method, cfg.SecretKey)
+	case "OIDC":
+		// 纯验证模式：令牌全部由外部身份提供方签发
+		return newOIDCVerifier(cfg.OIDC)
 	default:
 		return nil, fmt.Errorf("不支持的签名方法: %s", cfg.SigningMethod)
 	}
 }
 
 // GenerateToken 生成访问令牌
-func (j *JWTUtil) GenerateToken(userInfo UserInfo) (string, int, error) {
+func (j *JWTUtil) GenerateToken(userInfo UserInfo, family string) (string, int, error) {
 	if j.config.AccessTokenExpire <= 0 {
 		return "", 0, errors.New("访问令牌过期时间必须大于0")
 	}
 	expiresIn := j.config.AccessTokenExpire * 60 // 分钟转秒
 	expireTime := time.Now().Add(time.Duration(j.config.AccessTokenExpire) * time.Minute)
 
-	token, err := j.generateToken(userInfo, expireTime)
+	token, _, err := j.generateToken(userInfo, TokenTypeAccess, family, expireTime)
 	if err != nil {
 		return "", 0, err
 	}
 	return token, expiresIn, nil
 }
 
-// GenerateRefreshToken 生成刷新令牌
-func (j *JWTUtil) GenerateRefreshToken(userId string) (string, error) {
+// GenerateRefreshToken 生成刷新令牌，返回令牌及其 jti
+func (j *JWTUtil) GenerateRefreshToken(userId string, family string) (string, string, error) {
 	if j.config.RefreshTokenExpire <= 0 {
-		return "", errors.New("刷新令牌过期时间必须大于0")
+		return "", "", errors.New("刷新令牌过期时间必须大于0")
 	}
 	expireTime := time.Now().Add(time.Duration(j.config.RefreshTokenExpire) * time.Minute)
 
 	// 刷新令牌只需要用户ID
 	userInfo := UserInfo{Id: userId}
-	return j.generateToken(userInfo, expireTime)
+	return j.generateToken(userInfo, TokenTypeRefresh, family, expireTime)
+}
+
+// GenerateMFAToken 生成两步验证临时令牌，只携带用户ID，返回令牌及有效期（秒）
+func (j *JWTUtil) GenerateMFAToken(userId string) (string, int, error) {
+	token, _, err := j.generateToken(UserInfo{Id: userId}, TokenTypeMFAPending, "", time.Now().Add(mfaTokenTTL))
+	if err != nil {
+		return "", 0, err
+	}
+	return token, int(mfaTokenTTL.Seconds()), nil
+}
+
+// GenerateEmailVerifyToken 生成邮箱验证令牌，携带用户ID与待验证的邮箱
+func (j *JWTUtil) GenerateEmailVerifyToken(userId string, email string, ttl time.Duration) (string, error) {
+	token, _, err := j.generateToken(UserInfo{Id: userId, Email: email}, TokenTypeEmailVerify, "", time.Now().Add(ttl))
+	return token, err
+}
+
+// IssueTokenPair 签发一组访问令牌和刷新令牌
+// family 为空时开启新的令牌族（新登录），否则在已有令牌族中轮换
+func (j *JWTUtil) IssueTokenPair(ctx context.Context, userInfo UserInfo, family string) (*TokenPair, error) {
+	if family == "" {
+		family = uuid.New().String()
+	}
+
+	pair, jti, err := j.newTokenPair(userInfo, family)
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录令牌族当前唯一有效的刷新令牌
+	if err := j.revocation.SetFamilyCurrent(ctx, family, jti, j.refreshTokenTTL()); err != nil {
+		return nil, fmt.Errorf("记录刷新令牌失败: %w", err)
+	}
+	return pair, nil
+}
+
+// newTokenPair 生成令牌对，返回刷新令牌的 jti，不记录令牌族状态
+func (j *JWTUtil) newTokenPair(userInfo UserInfo, family string) (*TokenPair, string, error) {
+	accessToken, expiresIn, err := j.GenerateToken(userInfo, family)
+	if err != nil {
+		return nil, "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+
+	refreshToken, jti, err := j.GenerateRefreshToken(userInfo.Id, family)
+	if err != nil {
+		return nil, "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        expiresIn,
+		Family:           family,
+		RefreshExpiresAt: time.Now().Add(j.refreshTokenTTL()),
+	}, jti, nil
+}
+
+// JWKS 返回用于验证本服务令牌的公钥集合
+// 对称算法（HMAC）的密钥不能公开，返回空集合
+func (j *JWTUtil) JWKS() (JWKSet, error) {
+	provider, ok := j.signer.(KeySetProvider)
+	if !ok {
+		return JWKSet{Keys: []JWK{}}, nil
+	}
+	return provider.JWKS()
 }
 
 // ParseToken 解析并验证令牌
@@ -124,21 +248,51 @@ func (j *JWTUtil) ParseToken(tokenString string) (*Claims, error) {
 	if tokenString == "" {
 		return nil, errors.New("令牌字符串不能为空")
 	}
+	// 由外部身份提供方签发的令牌交给 OIDC 验证器
+	if j.external != nil && tokenIssuer(tokenString) == j.external.issuer {
+		return j.external.Verify(tokenString)
+	}
 	return j.signer.Verify(tokenString)
 }
 
+// ParseTokenOfType 解析令牌并校验令牌类型
+func (j *JWTUtil) ParseTokenOfType(tokenString, tokenType string) (*Claims, error) {
+	claims, err := j.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenType {
+		return nil, ErrInvalidTokenType
+	}
+	return claims, nil
+}
+
 // generateToken 是内部通用的令牌生成函数
-func (j *JWTUtil) generateToken(userInfo UserInfo, expireTime time.Time) (string, error) {
+// 每个令牌都带有唯一的 jti，用于服务端吊销
+func (j *JWTUtil) generateToken(userInfo UserInfo, tokenType, family string, expireTime time.Time) (string, string, error) {
+	now := time.Now()
 	claims := Claims{
-		UserInfo: &userInfo,
+		UserInfo:   &userInfo,
+		TokenType:  tokenType,
+		Family:     family,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(expireTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    j.config.Issuer,
 		},
 	}
-	return j.signer.Sign(claims)
+	token, err := j.signer.Sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+	return token, claims.ID, nil
+}
+
+func (j *JWTUtil) refreshTokenTTL() time.Duration {
+	return time.Duration(j.config.RefreshTokenExpire) * time.Minute
 }
 
 // --- HMAC Signer ---
@@ -203,12 +357,32 @@ func (s *hmacSigner) Verify(tokenString string) (*Claims, error) {
 // --- RSA Signer ---
 
 type rsaSigner struct {
-	algorithm  jwt.SigningMethod
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	algorithm jwt.SigningMethod
+	keys      *keyRing
+}
+
+// rsaKeyLoader RSA 密钥的加载与校验方式
+var rsaKeyLoader = keyLoader{
+	parsePrivate: func(filePath string) (crypto.Signer, error) {
+		return parseRSAPrivateKey(filePath)
+	},
+	parsePublic: func(filePath string) (crypto.PublicKey, error) {
+		return parseRSAPublicKey(filePath)
+	},
+	validate: func(privateKey crypto.Signer, publicKey crypto.PublicKey) error {
+		rsaPrivate, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return fmt.Errorf("私钥不是 RSA 密钥: %T", privateKey)
+		}
+		rsaPublic, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("公钥不是 RSA 密钥: %T", publicKey)
+		}
+		return validateKeyPair(rsaPrivate, rsaPublic)
+	},
 }
 
-func newRSASigner(method, privateKeyPath, publicKeyPath string) (Signer, error) {
+func newRSASigner(method string, cfg config.JWT) (Signer, error) {
 	var alg jwt.SigningMethod
 	switch method {
 	case "RS256":
@@ -221,46 +395,189 @@ func newRSASigner(method, privateKeyPath, publicKeyPath string) (Signer, error)
 		return nil, fmt.Errorf("不支持的 RSA 算法: %s", method)
 	}
 
-	if privateKeyPath == "" || publicKeyPath == "" {
-		return nil, errors.New("RSA 算法需要配置私钥和公钥文件路径")
+	keys, err := newKeyRing(cfg, rsaKeyLoader)
+	if err != nil {
+		return nil, fmt.Errorf("加载 RSA 密钥失败: %w", err)
 	}
 
-	privateKey, err := parseRSAPrivateKey(privateKeyPath)
+	return &rsaSigner{
+		algorithm: alg,
+		keys:      keys,
+	}, nil
+}
+
+func (s *rsaSigner) GetAlgorithm() string {
+	return s.algorithm.Alg()
+}
+
+func (s *rsaSigner) Sign(claims Claims) (string, error) {
+	return s.keys.sign(s.algorithm, claims)
+}
+
+func (s *rsaSigner) Verify(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
+		}
+		return s.keys.verificationKey(token)
+	})
+
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("解析令牌失败: %w", err)
 	}
 
-	publicKey, err := parseRSAPublicKey(publicKeyPath)
-	if err != nil {
-		return nil, err
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("令牌无效")
+}
+
+func (s *rsaSigner) JWKS() (JWKSet, error) {
+	return s.keys.jwks(s.GetAlgorithm())
+}
+
+// --- ECDSA Signer ---
+
+type ecdsaSigner struct {
+	algorithm *jwt.SigningMethodECDSA
+	keys      *keyRing
+}
+
+func newECDSASigner(method string, cfg config.JWT) (Signer, error) {
+	var alg *jwt.SigningMethodECDSA
+	var curve elliptic.Curve
+	switch method {
+	case "ES256":
+		alg, curve = jwt.SigningMethodES256, elliptic.P256()
+	case "ES384":
+		alg, curve = jwt.SigningMethodES384, elliptic.P384()
+	case "ES512":
+		alg, curve = jwt.SigningMethodES512, elliptic.P521()
+	default:
+		return nil, fmt.Errorf("不支持的 ECDSA 算法: %s", method)
+	}
+
+	loader := keyLoader{
+		parsePrivate: func(filePath string) (crypto.Signer, error) {
+			return parseECPrivateKey(filePath)
+		},
+		parsePublic: func(filePath string) (crypto.PublicKey, error) {
+			publicKey, err := parseECPublicKey(filePath)
+			if err != nil {
+				return nil, err
+			}
+			// 每种 ES 算法固定对应一条曲线
+			if publicKey.Curve != curve {
+				return nil, fmt.Errorf("公钥曲线 %s 与算法 %s 不匹配 [%s]", publicKey.Curve.Params().Name, method, filePath)
+			}
+			return publicKey, nil
+		},
+		validate: func(privateKey crypto.Signer, publicKey crypto.PublicKey) error {
+			ecPrivate, ok := privateKey.(*ecdsa.PrivateKey)
+			if !ok {
+				return fmt.Errorf("私钥不是 ECDSA 密钥: %T", privateKey)
+			}
+			ecPublic, ok := publicKey.(*ecdsa.PublicKey)
+			if !ok {
+				return fmt.Errorf("公钥不是 ECDSA 密钥: %T", publicKey)
+			}
+			return validateECKeyPair(ecPrivate, ecPublic)
+		},
 	}
 
-	if err := validateKeyPair(privateKey, publicKey); err != nil {
-		return nil, fmt.Errorf("RSA 密钥对验证失败: %w", err)
+	keys, err := newKeyRing(cfg, loader)
+	if err != nil {
+		return nil, fmt.Errorf("加载 ECDSA 密钥失败: %w", err)
 	}
 
-	return &rsaSigner{
-		algorithm:  alg,
-		privateKey: privateKey,
-		publicKey:  publicKey,
+	return &ecdsaSigner{
+		algorithm: alg,
+		keys:      keys,
 	}, nil
 }
 
-func (s *rsaSigner) GetAlgorithm() string {
+func (s *ecdsaSigner) GetAlgorithm() string {
 	return s.algorithm.Alg()
 }
 
-func (s *rsaSigner) Sign(claims Claims) (string, error) {
-	token := jwt.NewWithClaims(s.algorithm, claims)
-	return token.SignedString(s.privateKey)
+func (s *ecdsaSigner) Sign(claims Claims) (string, error) {
+	return s.keys.sign(s.algorithm, claims)
 }
 
-func (s *rsaSigner) Verify(tokenString string) (*Claims, error) {
+func (s *ecdsaSigner) Verify(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
+		}
+		return s.keys.verificationKey(token)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("解析令牌失败: %w", err)
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("令牌无效")
+}
+
+func (s *ecdsaSigner) JWKS() (JWKSet, error) {
+	return s.keys.jwks(s.GetAlgorithm())
+}
+
+// --- EdDSA Signer ---
+
+type eddsaSigner struct {
+	keys *keyRing
+}
+
+// eddsaKeyLoader Ed25519 密钥的加载与校验方式
+var eddsaKeyLoader = keyLoader{
+	parsePrivate: func(filePath string) (crypto.Signer, error) {
+		return parseEdPrivateKey(filePath)
+	},
+	parsePublic: func(filePath string) (crypto.PublicKey, error) {
+		return parseEdPublicKey(filePath)
+	},
+	validate: func(privateKey crypto.Signer, publicKey crypto.PublicKey) error {
+		edPrivate, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return fmt.Errorf("私钥不是 Ed25519 密钥: %T", privateKey)
+		}
+		edPublic, ok := publicKey.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("公钥不是 Ed25519 密钥: %T", publicKey)
+		}
+		return validateEdKeyPair(edPrivate, edPublic)
+	},
+}
+
+func newEdDSASigner(cfg config.JWT) (Signer, error) {
+	keys, err := newKeyRing(cfg, eddsaKeyLoader)
+	if err != nil {
+		return nil, fmt.Errorf("加载 Ed25519 密钥失败: %w", err)
+	}
+
+	return &eddsaSigner{keys: keys}, nil
+}
+
+func (s *eddsaSigner) GetAlgorithm() string {
+	return jwt.SigningMethodEdDSA.Alg()
+}
+
+func (s *eddsaSigner) Sign(claims Claims) (string, error) {
+	return s.keys.sign(jwt.SigningMethodEdDSA, claims)
+}
+
+func (s *eddsaSigner) Verify(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
 			return nil, fmt.Errorf("意外的签名方法: %v", token.Header["alg"])
 		}
-		return s.publicKey, nil
+		return s.keys.verificationKey(token)
 	})
 
 	if err != nil {
@@ -274,6 +591,10 @@ func (s *rsaSigner) Verify(tokenString string) (*Claims, error) {
 	return nil, errors.New("令牌无效")
 }
 
+func (s *eddsaSigner) JWKS() (JWKSet, error) {
+	return s.keys.jwks(s.GetAlgorithm())
+}
+
 func parseRSAPrivateKey(filePath string) (*rsa.PrivateKey, error) {
 	keyData, err := os.ReadFile(filePath)
 	if err != nil {
@@ -307,3 +628,80 @@ func validateKeyPair(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) error
 
 	return nil
 }
+
+func parseECPrivateKey(filePath string) (*ecdsa.PrivateKey, error) {
+	keyData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥文件失败 [%s]: %w", filePath, err)
+	}
+	return jwt.ParseECPrivateKeyFromPEM(keyData)
+}
+
+func parseECPublicKey(filePath string) (*ecdsa.PublicKey, error) {
+	keyData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取公钥文件失败 [%s]: %w", filePath, err)
+	}
+	return jwt.ParseECPublicKeyFromPEM(keyData)
+}
+
+func validateECKeyPair(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey) error {
+	testData := []byte("key-pair-validation")
+	hash := crypto.SHA256.New()
+	hash.Write(testData)
+	digest := hash.Sum(nil)
+
+	signature, err := ecdsa.SignASN1(rand.Reader, privateKey, digest)
+	if err != nil {
+		return fmt.Errorf("使用私钥签名失败: %w", err)
+	}
+
+	if !ecdsa.VerifyASN1(publicKey, digest, signature) {
+		return errors.New("使用公钥验证签名失败")
+	}
+
+	return nil
+}
+
+func parseEdPrivateKey(filePath string) (ed25519.PrivateKey, error) {
+	keyData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取私钥文件失败 [%s]: %w", filePath, err)
+	}
+	key, err := jwt.ParseEdPrivateKeyFromPEM(keyData)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("私钥不是 Ed25519 密钥 [%s]", filePath)
+	}
+	return privateKey, nil
+}
+
+func parseEdPublicKey(filePath string) (ed25519.PublicKey, error) {
+	keyData, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取公钥文件失败 [%s]: %w", filePath, err)
+	}
+	key, err := jwt.ParseEdPublicKeyFromPEM(keyData)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("公钥不是 Ed25519 密钥 [%s]", filePath)
+	}
+	return publicKey, nil
+}
+
+func validateEdKeyPair(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) error {
+	testData := []byte("key-pair-validation")
+
+	signature := ed25519.Sign(privateKey, testData)
+	if !ed25519.Verify(publicKey, testData, signature) {
+		return errors.New("使用公钥验证签名失败")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"mygoframe/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyLoader 描述某类非对称算法的密钥加载与校验方式
+type keyLoader struct {
+	parsePrivate func(filePath string) (crypto.Signer, error)
+	parsePublic  func(filePath string) (crypto.PublicKey, error)
+	validate     func(privateKey crypto.Signer, publicKey crypto.PublicKey) error
+}
+
+// keyRing 密钥环：一个当前用于签名的活动密钥，以及若干仅用于验证的已退役密钥
+// 轮换密钥时新密钥成为活动密钥，旧密钥保留在密钥环中直到其签发的令牌全部过期
+type keyRing struct {
+	activeKid  string
+	signingKey crypto.Signer
+	publicKeys map[string]crypto.PublicKey
+	kids       []string // 保持配置顺序，用于输出 JWKS
+}
+
+// newKeyRing 根据配置加载密钥环
+// 未配置 keys 时兼容旧的 private-key-path/public-key-path 单密钥配置，kid 取公钥的 JWK 指纹
+func newKeyRing(cfg config.JWT, loader keyLoader) (*keyRing, error) {
+	keys := cfg.Keys
+	activeKid := cfg.ActiveKeyID
+
+	if len(keys) == 0 {
+		if cfg.PrivateKeyPath == "" || cfg.PublicKeyPath == "" {
+			return nil, errors.New("非对称算法需要配置私钥和公钥文件路径")
+		}
+		keys = []config.JWTKey{{
+			PrivateKeyPath: cfg.PrivateKeyPath,
+			PublicKeyPath:  cfg.PublicKeyPath,
+		}}
+	} else if activeKid == "" {
+		return nil, errors.New("配置了密钥环时必须指定 active-key-id")
+	}
+
+	ring := &keyRing{
+		publicKeys: make(map[string]crypto.PublicKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		if key.PublicKeyPath == "" {
+			return nil, fmt.Errorf("密钥 [%s] 未配置公钥文件路径", key.Kid)
+		}
+		publicKey, err := loader.parsePublic(key.PublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := key.Kid
+		if kid == "" {
+			if len(cfg.Keys) > 0 {
+				return nil, errors.New("密钥环中的每个密钥都必须配置 kid")
+			}
+			kid, err = JWKThumbprint(publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("计算密钥指纹失败: %w", err)
+			}
+			activeKid = kid
+		}
+		if _, exists := ring.publicKeys[kid]; exists {
+			return nil, fmt.Errorf("密钥 kid 重复: %s", kid)
+		}
+
+		if kid == activeKid {
+			if key.PrivateKeyPath == "" {
+				return nil, fmt.Errorf("活动密钥 [%s] 未配置私钥文件路径", kid)
+			}
+			privateKey, err := loader.parsePrivate(key.PrivateKeyPath)
+			if err != nil {
+				return nil, err
+			}
+			if err := loader.validate(privateKey, publicKey); err != nil {
+				return nil, fmt.Errorf("密钥对 [%s] 验证失败: %w", kid, err)
+			}
+			ring.signingKey = privateKey
+		}
+
+		ring.publicKeys[kid] = publicKey
+		ring.kids = append(ring.kids, kid)
+	}
+
+	if ring.signingKey == nil {
+		return nil, fmt.Errorf("密钥环中不存在活动密钥: %s", activeKid)
+	}
+	ring.activeKid = activeKid
+
+	return ring, nil
+}
+
+// sign 使用活动密钥签名，并在头部写入 kid
+func (r *keyRing) sign(method jwt.SigningMethod, claims Claims) (string, error) {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = r.activeKid
+	return token.SignedString(r.signingKey)
+}
+
+// verificationKey 根据令牌头部的 kid 选择验证公钥
+// 未携带 kid 的令牌（密钥环启用前签发）依次尝试所有公钥
+func (r *keyRing) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid != "" {
+		publicKey, ok := r.publicKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("未知的密钥: %s", kid)
+		}
+		return publicKey, nil
+	}
+
+	keySet := jwt.VerificationKeySet{}
+	for _, id := range r.kids {
+		keySet.Keys = append(keySet.Keys, r.publicKeys[id])
+	}
+	return keySet, nil
+}
+
+// jwks 导出密钥环中所有公钥
+func (r *keyRing) jwks(alg string) (JWKSet, error) {
+	set := JWKSet{Keys: make([]JWK, 0, len(r.kids))}
+	for _, kid := range r.kids {
+		jwk, err := NewJWK(r.publicKeys[kid], kid, alg)
+		if err != nil {
+			return JWKSet{}, err
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set, nil
+}
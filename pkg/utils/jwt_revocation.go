@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"mygoframe/pkg/cache"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrTokenRevoked 表示令牌已被吊销
+	ErrTokenRevoked = errors.New("令牌已被吊销")
+	// ErrRefreshTokenReused 表示已使用过的刷新令牌被再次提交，整个令牌族已被吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，疑似泄露")
+)
+
+// RevocationStore 定义了令牌吊销记录的存储接口
+type RevocationStore interface {
+	// RevokeJTI 吊销单个令牌，记录保留 ttl 时长（通常为令牌剩余有效期）
+	RevokeJTI(ctx context.Context, jti string, ttl time.Duration) error
+	// IsJTIRevoked 判断单个令牌是否已被吊销
+	IsJTIRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUserBefore 吊销用户在 before 之前签发的所有令牌
+	RevokeUserBefore(ctx context.Context, userID string, before time.Time, ttl time.Duration) error
+	// UserRevokedBefore 返回用户的吊销时间点，未设置时返回零值
+	UserRevokedBefore(ctx context.Context, userID string) (time.Time, error)
+	// SetFamilyCurrent 记录令牌族当前唯一有效的刷新令牌 jti
+	SetFamilyCurrent(ctx context.Context, family, jti string, ttl time.Duration) error
+	// FamilyCurrent 返回令牌族当前有效的刷新令牌 jti，令牌族不存在时返回空字符串
+	FamilyCurrent(ctx context.Context, family string) (string, error)
+	// RotateFamily 原子地将刷新令牌 usedJTI 标记为已使用，并将令牌族当前的刷新令牌改为 jti
+	// usedJTI 已被使用过时不做修改并返回 false
+	RotateFamily(ctx context.Context, family, usedJTI, jti string, usedTTL, ttl time.Duration) (bool, error)
+	// RevokeFamily 吊销整个令牌族
+	RevokeFamily(ctx context.Context, family string, ttl time.Duration) error
+	// IsFamilyRevoked 判断令牌族是否已被吊销
+	IsFamilyRevoked(ctx context.Context, family string) (bool, error)
+}
+
+// cacheRevocationStore 基于 pkg/cache 默认存储的吊销记录实现
+// 启用 Redis 时记录存放在 Redis 中，否则降级到本地缓存
+type cacheRevocationStore struct{}
+
+// NewCacheRevocationStore 创建基于缓存的吊销记录存储
+func NewCacheRevocationStore() RevocationStore {
+	return &cacheRevocationStore{}
+}
+
+func revokedJTIKey(jti string) string {
+	return fmt.Sprintf("jwt:revoked:%s", jti)
+}
+
+func revokedBeforeKey(userID string) string {
+	return fmt.Sprintf("jwt:revoked_before:%s", userID)
+}
+
+func familyCurrentKey(family string) string {
+	return fmt.Sprintf("jwt:family:%s", family)
+}
+
+func usedRefreshKey(jti string) string {
+	return fmt.Sprintf("jwt:refresh_used:%s", jti)
+}
+
+func revokedFamilyKey(family string) string {
+	return fmt.Sprintf("jwt:revoked_family:%s", family)
+}
+
+func (s *cacheRevocationStore) RevokeJTI(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		// 令牌已自然过期，无需记录
+		return nil
+	}
+	return cache.Put(ctx, revokedJTIKey(jti), "1", ttl)
+}
+
+func (s *cacheRevocationStore) IsJTIRevoked(ctx context.Context, jti string) (bool, error) {
+	return cache.Has(ctx, revokedJTIKey(jti))
+}
+
+func (s *cacheRevocationStore) RevokeUserBefore(ctx context.Context, userID string, before time.Time, ttl time.Duration) error {
+	return cache.Put(ctx, revokedBeforeKey(userID), strconv.FormatInt(before.UnixMilli(), 10), ttl)
+}
+
+func (s *cacheRevocationStore) UserRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	val, err := cache.Get(ctx, revokedBeforeKey(userID))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	ts, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析吊销时间失败: %w", err)
+	}
+	return time.UnixMilli(ts), nil
+}
+
+func (s *cacheRevocationStore) SetFamilyCurrent(ctx context.Context, family, jti string, ttl time.Duration) error {
+	return cache.Put(ctx, familyCurrentKey(family), jti, ttl)
+}
+
+func (s *cacheRevocationStore) FamilyCurrent(ctx context.Context, family string) (string, error) {
+	jti, err := cache.Get(ctx, familyCurrentKey(family))
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return "", nil
+	}
+	return jti, err
+}
+
+// RotateFamily 通过 cache.Add 写入已使用标记，并发提交同一刷新令牌时只有一个请求能写入
+// 更新令牌族失败时撤销标记，客户端可用原刷新令牌重试
+func (s *cacheRevocationStore) RotateFamily(ctx context.Context, family, usedJTI, jti string, usedTTL, ttl time.Duration) (bool, error) {
+	added, err := cache.Add(ctx, usedRefreshKey(usedJTI), "1", usedTTL)
+	if err != nil || !added {
+		return false, err
+	}
+	if err := s.SetFamilyCurrent(ctx, family, jti, ttl); err != nil {
+		_ = cache.Forget(ctx, usedRefreshKey(usedJTI))
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *cacheRevocationStore) RevokeFamily(ctx context.Context, family string, ttl time.Duration) error {
+	if err := cache.Put(ctx, revokedFamilyKey(family), "1", ttl); err != nil {
+		return err
+	}
+	return cache.Forget(ctx, familyCurrentKey(family))
+}
+
+func (s *cacheRevocationStore) IsFamilyRevoked(ctx context.Context, family string) (bool, error) {
+	return cache.Has(ctx, revokedFamilyKey(family))
+}
+
+// RevokeToken 吊销单个令牌，吊销记录保留到令牌自然过期
+func (j *JWTUtil) RevokeToken(ctx context.Context, claims *Claims) error {
+	if claims.ID == "" {
+		return errors.New("令牌缺少 jti，无法吊销")
+	}
+
+	var ttl time.Duration
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	return j.revocation.RevokeJTI(ctx, claims.ID, ttl)
+}
+
+// RevokeUserTokens 吊销用户在 before 之前签发的所有令牌（即"退出所有设备"）
+// 吊销时间点精确到毫秒，与 before 处于同一毫秒内签发的令牌同样会被视为已吊销
+func (j *JWTUtil) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	if userID == "" {
+		return errors.New("用户ID不能为空")
+	}
+
+	return j.revocation.RevokeUserBefore(ctx, userID, before, j.maxTokenTTL())
+}
+
+// RevokeFamily 吊销整个令牌族，该族内的访问令牌和刷新令牌均失效
+func (j *JWTUtil) RevokeFamily(ctx context.Context, family string) error {
+	if family == "" {
+		return errors.New("令牌族ID不能为空")
+	}
+	return j.revocation.RevokeFamily(ctx, family, j.maxTokenTTL())
+}
+
+// ValidateRefreshToken 校验刷新令牌是否为令牌族当前有效的刷新令牌，不消费令牌
+// 已轮换过的刷新令牌再次出现时视为令牌被盗用，吊销整个令牌族
+func (j *JWTUtil) ValidateRefreshToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims, err := j.ParseTokenOfType(tokenString, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Family == "" {
+		return nil, errors.New("刷新令牌缺少令牌族信息")
+	}
+
+	revoked, err := j.revocation.IsFamilyRevoked(ctx, claims.Family)
+	if err != nil {
+		return nil, fmt.Errorf("查询令牌族吊销状态失败: %w", err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	current, err := j.revocation.FamilyCurrent(ctx, claims.Family)
+	if err != nil {
+		return nil, fmt.Errorf("查询令牌族状态失败: %w", err)
+	}
+	if current == "" {
+		// 令牌族记录已过期或不存在
+		return nil, ErrTokenRevoked
+	}
+	if current != claims.ID {
+		return nil, j.reuseDetected(ctx, claims.Family)
+	}
+
+	if err := j.CheckRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RotateRefreshToken 消费已通过 ValidateRefreshToken 校验的刷新令牌，并在同一令牌族中签发新的令牌对
+// 消费与更新令牌族是一步原子操作，并发提交同一刷新令牌时只有一个请求成功，其余视为重复使用并吊销整个令牌族
+// 应在其他检查都通过后最后调用，避免令牌已消费而新令牌未能签发
+func (j *JWTUtil) RotateRefreshToken(ctx context.Context, claims *Claims, userInfo UserInfo) (*TokenPair, error) {
+	pair, jti, err := j.newTokenPair(userInfo, claims.Family)
+	if err != nil {
+		return nil, err
+	}
+
+	usedTTL := j.refreshTokenTTL()
+	if claims.ExpiresAt != nil {
+		usedTTL = time.Until(claims.ExpiresAt.Time)
+	}
+	if usedTTL <= 0 {
+		return nil, jwt.ErrTokenExpired
+	}
+
+	rotated, err := j.revocation.RotateFamily(ctx, claims.Family, claims.ID, jti, usedTTL, j.refreshTokenTTL())
+	if err != nil {
+		return nil, fmt.Errorf("轮换刷新令牌失败: %w", err)
+	}
+	if !rotated {
+		return nil, j.reuseDetected(ctx, claims.Family)
+	}
+	return pair, nil
+}
+
+// reuseDetected 刷新令牌被重复使用，吊销整个令牌族
+func (j *JWTUtil) reuseDetected(ctx context.Context, family string) error {
+	if err := j.RevokeFamily(ctx, family); err != nil {
+		return fmt.Errorf("吊销令牌族失败: %w", err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// maxTokenTTL 返回访问令牌与刷新令牌中较长的有效期，吊销记录需保留到该时长
+func (j *JWTUtil) maxTokenTTL() time.Duration {
+	ttl := j.refreshTokenTTL()
+	if access := time.Duration(j.config.AccessTokenExpire) * time.Minute; access > ttl {
+		ttl = access
+	}
+	return ttl
+}
+
+// CheckRevoked 检查令牌是否已被吊销，已吊销时返回 ErrTokenRevoked
+func (j *JWTUtil) CheckRevoked(ctx context.Context, claims *Claims) error {
+	if claims.ID != "" {
+		revoked, err := j.revocation.IsJTIRevoked(ctx, claims.ID)
+		if err != nil {
+			return fmt.Errorf("查询令牌吊销状态失败: %w", err)
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	if claims.UserInfo != nil && claims.UserInfo.Id != "" && (claims.IssuedAtMs != 0 || claims.IssuedAt != nil) {
+		before, err := j.revocation.UserRevokedBefore(ctx, claims.UserInfo.Id)
+		if err != nil {
+			return fmt.Errorf("查询用户令牌吊销状态失败: %w", err)
+		}
+		if !before.IsZero() && issuedNotAfter(claims, before) {
+			return ErrTokenRevoked
+		}
+	}
+
+	if claims.Family != "" {
+		revoked, err := j.revocation.IsFamilyRevoked(ctx, claims.Family)
+		if err != nil {
+			return fmt.Errorf("查询令牌族吊销状态失败: %w", err)
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	return nil
+}
+
+// issuedNotAfter 判断令牌是否在 before 之前（含）签发
+// 优先使用毫秒精度的 iat_ms；外部签发的令牌只有按秒的 iat，与 before 处于同一秒时无法区分先后，视为已吊销
+func issuedNotAfter(claims *Claims, before time.Time) bool {
+	if claims.IssuedAtMs != 0 {
+		return claims.IssuedAtMs <= before.UnixMilli()
+	}
+	return claims.IssuedAt.Unix() <= before.Unix()
+}
+
+// ValidateToken 解析令牌，校验令牌类型并检查吊销状态
+func (j *JWTUtil) ValidateToken(ctx context.Context, tokenString, tokenType string) (*Claims, error) {
+	claims, err := j.ParseTokenOfType(tokenString, tokenType)
+	if err != nil {
+		return nil, err
+	}
+	if err := j.CheckRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,498 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestJWTUtil(t *testing.T) *JWTUtil {
+	t.Helper()
+
+	cfg := &config.Config{
+		Redis: config.Redis{
+			Enabled: false, // 使用本地缓存测试
+		},
+		LocalCache: config.LocalCache{
+			MaxCost: 1 << 20,
+			MaxKeys: 1e4,
+		},
+	}
+	if err := cache.Init(cfg); err != nil {
+		t.Fatalf("初始化缓存失败: %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+
+	jwtCfg := config.JWT{
+		SigningMethod:      "HS256",
+		SecretKey:          "test-secret-key-0123456789abcdef",
+		AccessTokenExpire:  10,
+		RefreshTokenExpire: 60,
+		Issuer:             "test",
+	}
+	signer, err := newSigner(jwtCfg)
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+
+	return &JWTUtil{
+		config:     jwtCfg,
+		signer:     signer,
+		revocation: NewCacheRevocationStore(),
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	claims, err := j.ValidateToken(ctx, tokens.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("验证访问令牌失败: %v", err)
+	}
+	if claims.ID == "" {
+		t.Fatal("令牌缺少 jti")
+	}
+
+	if err := j.RevokeToken(ctx, claims); err != nil {
+		t.Fatalf("吊销令牌失败: %v", err)
+	}
+	if _, err := j.ValidateToken(ctx, tokens.AccessToken, TokenTypeAccess); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("已吊销的令牌应校验失败, 实际: %v", err)
+	}
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	if err := j.RevokeUserTokens(ctx, "u1", time.Now()); err != nil {
+		t.Fatalf("吊销用户令牌失败: %v", err)
+	}
+	if _, err := j.ValidateToken(ctx, tokens.AccessToken, TokenTypeAccess); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("退出所有设备后访问令牌应失效, 实际: %v", err)
+	}
+	if _, err := j.ValidateRefreshToken(ctx, tokens.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("退出所有设备后刷新令牌应失效, 实际: %v", err)
+	}
+}
+
+func TestTokenTypeEnforced(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	if _, err := j.ValidateToken(ctx, tokens.RefreshToken, TokenTypeAccess); !errors.Is(err, ErrInvalidTokenType) {
+		t.Errorf("刷新令牌不能作为访问令牌使用, 实际: %v", err)
+	}
+	if _, err := j.ValidateRefreshToken(ctx, tokens.AccessToken); !errors.Is(err, ErrInvalidTokenType) {
+		t.Errorf("访问令牌不能用于刷新, 实际: %v", err)
+	}
+}
+
+func TestMFATokenCannotAccess(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	token, _, err := j.GenerateMFAToken("u1")
+	if err != nil {
+		t.Fatalf("签发两步验证令牌失败: %v", err)
+	}
+
+	if _, err := j.ValidateToken(ctx, token, TokenTypeAccess); !errors.Is(err, ErrInvalidTokenType) {
+		t.Errorf("两步验证令牌不能作为访问令牌使用, 实际: %v", err)
+	}
+	claims, err := j.ValidateToken(ctx, token, TokenTypeMFAPending)
+	if err != nil {
+		t.Fatalf("验证两步验证令牌失败: %v", err)
+	}
+
+	// 使用后吊销，不能重复使用
+	if err := j.RevokeToken(ctx, claims); err != nil {
+		t.Fatalf("吊销令牌失败: %v", err)
+	}
+	if _, err := j.ValidateToken(ctx, token, TokenTypeMFAPending); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("已使用的两步验证令牌应失效, 实际: %v", err)
+	}
+}
+
+func TestEmailVerifyToken(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	token, err := j.GenerateEmailVerifyToken("u1", "a@example.com", time.Hour)
+	if err != nil {
+		t.Fatalf("签发邮箱验证令牌失败: %v", err)
+	}
+
+	if _, err := j.ValidateToken(ctx, token, TokenTypeAccess); !errors.Is(err, ErrInvalidTokenType) {
+		t.Errorf("邮箱验证令牌不能作为访问令牌使用, 实际: %v", err)
+	}
+	claims, err := j.ValidateToken(ctx, token, TokenTypeEmailVerify)
+	if err != nil {
+		t.Fatalf("验证邮箱验证令牌失败: %v", err)
+	}
+	if claims.UserInfo.Id != "u1" || claims.UserInfo.Email != "a@example.com" {
+		t.Errorf("令牌声明不正确: %+v", claims.UserInfo)
+	}
+}
+
+func TestRefreshTokenRotationAndReuse(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	first, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	claims, err := j.ValidateRefreshToken(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("校验刷新令牌失败: %v", err)
+	}
+	// 校验不消费令牌，轮换前可以重复校验
+	if _, err := j.ValidateRefreshToken(ctx, first.RefreshToken); err != nil {
+		t.Fatalf("轮换前重复校验失败: %v", err)
+	}
+	second, err := j.RotateRefreshToken(ctx, claims, UserInfo{Id: "u1"})
+	if err != nil {
+		t.Fatalf("轮换令牌失败: %v", err)
+	}
+	if second.Family != first.Family {
+		t.Errorf("轮换后令牌族应保持不变: %s != %s", second.Family, first.Family)
+	}
+
+	// 旧刷新令牌被再次使用，整个令牌族应被吊销
+	if _, err := j.ValidateRefreshToken(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("重复使用刷新令牌应被检测, 实际: %v", err)
+	}
+	if _, err := j.ValidateRefreshToken(ctx, second.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("令牌族吊销后新刷新令牌应失效, 实际: %v", err)
+	}
+	if _, err := j.ValidateToken(ctx, second.AccessToken, TokenTypeAccess); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("令牌族吊销后访问令牌应失效, 实际: %v", err)
+	}
+}
+
+func TestConcurrentRefreshDetectedAsReuse(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+
+	// 同一刷新令牌被并发提交（如被盗用后与合法客户端竞争），只有一个请求能完成轮换
+	var rotated, reused int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claims, err := j.ValidateRefreshToken(ctx, tokens.RefreshToken)
+			if err == nil {
+				_, err = j.RotateRefreshToken(ctx, claims, UserInfo{Id: "u1"})
+			}
+			switch {
+			case err == nil:
+				atomic.AddInt32(&rotated, 1)
+			case errors.Is(err, ErrRefreshTokenReused) || errors.Is(err, ErrTokenRevoked):
+				atomic.AddInt32(&reused, 1)
+			default:
+				t.Errorf("刷新失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if rotated > 1 || reused == 0 {
+		t.Fatalf("成功轮换 %d 次, 检测到重复使用 %d 次, 期望最多 1 次成功且检测到重复使用", rotated, reused)
+	}
+	if revoked, _ := j.revocation.IsFamilyRevoked(ctx, tokens.Family); !revoked {
+		t.Error("检测到重复使用后应吊销整个令牌族")
+	}
+}
+
+// writeKeyPair 将密钥对以 PEM 格式写入临时目录，返回私钥和公钥文件路径
+func writeKeyPair(t *testing.T, dir, name string, privateKey crypto.Signer) (string, string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatalf("编码公钥失败: %v", err)
+	}
+
+	privatePath := filepath.Join(dir, name+".key")
+	publicPath := filepath.Join(dir, name+".pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0600); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0644); err != nil {
+		t.Fatalf("写入公钥失败: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+// writeRSAKeyPair 生成 RSA 密钥对并写入临时目录
+func writeRSAKeyPair(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+	return writeKeyPair(t, dir, name, privateKey)
+}
+
+func TestRSAKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeRSAKeyPair(t, dir, "old")
+	newPrivate, newPublic := writeRSAKeyPair(t, dir, "new")
+
+	before, err := newSigner(config.JWT{
+		SigningMethod: "RS256",
+		ActiveKeyID:   "old",
+		Keys: []config.JWTKey{
+			{Kid: "old", PrivateKeyPath: oldPrivate, PublicKeyPath: oldPublic},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+	oldToken, err := before.Sign(Claims{UserInfo: &UserInfo{Id: "u1"}})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+
+	// 轮换：新密钥成为活动密钥，旧密钥仅保留公钥用于验证
+	after, err := newSigner(config.JWT{
+		SigningMethod: "RS256",
+		ActiveKeyID:   "new",
+		Keys: []config.JWTKey{
+			{Kid: "new", PrivateKeyPath: newPrivate, PublicKeyPath: newPublic},
+			{Kid: "old", PublicKeyPath: oldPublic},
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+
+	if _, err := after.Verify(oldToken); err != nil {
+		t.Errorf("轮换后旧令牌应仍可验证: %v", err)
+	}
+
+	newToken, err := after.Sign(Claims{UserInfo: &UserInfo{Id: "u1"}})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if _, err := before.Verify(newToken); err == nil {
+		t.Error("旧签名器不应验证新密钥签发的令牌")
+	}
+
+	jwks, err := after.(KeySetProvider).JWKS()
+	if err != nil {
+		t.Fatalf("导出 JWKS 失败: %v", err)
+	}
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "new" || jwks.Keys[1].Kid != "old" {
+		t.Errorf("JWKS 内容不符合预期: %+v", jwks.Keys)
+	}
+}
+
+func TestRSALegacyKeyPair(t *testing.T) {
+	privatePath, publicPath := writeRSAKeyPair(t, t.TempDir(), "legacy")
+
+	signer, err := newSigner(config.JWT{
+		SigningMethod:  "RS256",
+		PrivateKeyPath: privatePath,
+		PublicKeyPath:  publicPath,
+	})
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+
+	token, err := signer.Sign(Claims{UserInfo: &UserInfo{Id: "u1"}})
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	claims, err := signer.Verify(token)
+	if err != nil {
+		t.Fatalf("验证失败: %v", err)
+	}
+	if claims.UserInfo.Id != "u1" {
+		t.Errorf("用户ID不匹配: %s", claims.UserInfo.Id)
+	}
+}
+
+func TestAsymmetricSigners(t *testing.T) {
+	newECKey := func(curve elliptic.Curve) crypto.Signer {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("生成 ECDSA 密钥失败: %v", err)
+		}
+		return key
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("生成 Ed25519 密钥失败: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		alg    string
+		key    crypto.Signer
+		kty    string
+	}{
+		{"ES256", "ES256", newECKey(elliptic.P256()), "EC"},
+		{"ES384", "ES384", newECKey(elliptic.P384()), "EC"},
+		{"ES512", "ES512", newECKey(elliptic.P521()), "EC"},
+		{"EdDSA", "EdDSA", edKey, "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			privatePath, publicPath := writeKeyPair(t, t.TempDir(), "key", tt.key)
+
+			signer, err := newSigner(config.JWT{
+				SigningMethod:  tt.method,
+				PrivateKeyPath: privatePath,
+				PublicKeyPath:  publicPath,
+			})
+			if err != nil {
+				t.Fatalf("创建签名器失败: %v", err)
+			}
+			if signer.GetAlgorithm() != tt.alg {
+				t.Errorf("算法不匹配: 期望 %s, 实际 %s", tt.alg, signer.GetAlgorithm())
+			}
+
+			token, err := signer.Sign(Claims{UserInfo: &UserInfo{Id: "u1"}})
+			if err != nil {
+				t.Fatalf("签名失败: %v", err)
+			}
+			claims, err := signer.Verify(token)
+			if err != nil {
+				t.Fatalf("验证失败: %v", err)
+			}
+			if claims.UserInfo.Id != "u1" {
+				t.Errorf("用户ID不匹配: %s", claims.UserInfo.Id)
+			}
+
+			jwks, err := signer.(KeySetProvider).JWKS()
+			if err != nil {
+				t.Fatalf("导出 JWKS 失败: %v", err)
+			}
+			if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != tt.kty || jwks.Keys[0].Alg != tt.alg {
+				t.Errorf("JWKS 内容不符合预期: %+v", jwks.Keys)
+			}
+		})
+	}
+}
+
+func TestECDSACurveMismatch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成 ECDSA 密钥失败: %v", err)
+	}
+	privatePath, publicPath := writeKeyPair(t, t.TempDir(), "key", key)
+
+	_, err = newSigner(config.JWT{
+		SigningMethod:  "ES384",
+		PrivateKeyPath: privatePath,
+		PublicKeyPath:  publicPath,
+	})
+	if err == nil {
+		t.Error("P-256 密钥不应用于 ES384")
+	}
+}
+
+func TestTokenIssuedAfterRevokeUserTokens(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	// 修改密码等操作吊销旧令牌后立即重新登录，新令牌应可用
+	if err := j.RevokeUserTokens(ctx, "u1", time.Now()); err != nil {
+		t.Fatalf("吊销用户令牌失败: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	if _, err := j.ValidateToken(ctx, tokens.AccessToken, TokenTypeAccess); err != nil {
+		t.Errorf("吊销后签发的访问令牌应有效, 实际: %v", err)
+	}
+	if _, err := j.ValidateRefreshToken(ctx, tokens.RefreshToken); err != nil {
+		t.Errorf("吊销后签发的刷新令牌应有效, 实际: %v", err)
+	}
+}
+
+func TestRevokeUserTokensSecondPrecisionIat(t *testing.T) {
+	j := newTestJWTUtil(t)
+	ctx := context.Background()
+
+	// 签发的时间声明保持秒精度，毫秒时间只放在私有声明中
+	tokens, err := j.IssueTokenPair(ctx, UserInfo{Id: "u1"}, "")
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	claims, err := j.ParseTokenOfType(tokens.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("解析令牌失败: %v", err)
+	}
+	if claims.IssuedAt.Time.Nanosecond() != 0 || claims.IssuedAtMs == 0 {
+		t.Errorf("iat = %v, iat_ms = %d, 期望 iat 为整秒且带有 iat_ms", claims.IssuedAt.Time, claims.IssuedAtMs)
+	}
+
+	// 没有 iat_ms 的令牌按秒比较，与吊销时间点处于同一秒时视为已吊销
+	before := time.Now()
+	if err := j.RevokeUserTokens(ctx, "u1", before); err != nil {
+		t.Fatalf("吊销用户令牌失败: %v", err)
+	}
+	external := &Claims{UserInfo: &UserInfo{Id: "u1"}}
+	external.IssuedAt = jwt.NewNumericDate(before.Truncate(time.Second))
+	if err := j.CheckRevoked(ctx, external); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("同一秒签发的令牌 CheckRevoked = %v, 期望 ErrTokenRevoked", err)
+	}
+	external.IssuedAt = jwt.NewNumericDate(before.Truncate(time.Second).Add(time.Second))
+	if err := j.CheckRevoked(ctx, external); err != nil {
+		t.Errorf("下一秒签发的令牌 CheckRevoked = %v, 期望 nil", err)
+	}
+}
@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mygoframe/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// minJWKSRefetchInterval 遇到未知 kid 时重新拉取 JWKS 的最小间隔，防止被恶意令牌放大请求
+	minJWKSRefetchInterval = time.Minute
+	// defaultJWKSRefreshInterval 默认的 JWKS 定期刷新间隔
+	defaultJWKSRefreshInterval = 60 * time.Minute
+	// externalUserIDPrefix 外部令牌的用户标识前缀，与本地用户ID处于不同的命名空间，外部用户不能冒充同ID的本地用户
+	externalUserIDPrefix = "oidc:"
+)
+
+// oidcDiscovery OpenID Provider 元数据中需要用到的字段
+type oidcDiscovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// oidcVerifier 验证外部 OIDC 身份提供方（如 Casdoor、Keycloak）签发的令牌
+// 通过 /.well-known/openid-configuration 发现 JWKS 地址，缓存公钥并定期刷新
+type oidcVerifier struct {
+	issuer             string
+	audience           string
+	refreshInterval    time.Duration
+	minRefetchInterval time.Duration
+	httpClient         *http.Client
+
+	mu        sync.RWMutex
+	jwksURI   string
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func newOIDCVerifier(cfg config.OIDC) (*oidcVerifier, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("OIDC 模式需要配置 issuer")
+	}
+	// 不校验 aud 时会接受身份提供方签发给其他客户端的令牌
+	if cfg.Audience == "" {
+		return nil, errors.New("OIDC 模式需要配置 audience")
+	}
+
+	refreshInterval := time.Duration(cfg.RefreshInterval) * time.Minute
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	// 公钥在首次验证时才拉取，身份提供方暂时不可用不影响服务启动
+	return &oidcVerifier{
+		issuer:             cfg.Issuer,
+		audience:           cfg.Audience,
+		refreshInterval:    refreshInterval,
+		minRefetchInterval: minJWKSRefetchInterval,
+		httpClient:         &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (v *oidcVerifier) GetAlgorithm() string {
+	return "OIDC"
+}
+
+func (v *oidcVerifier) Sign(claims Claims) (string, error) {
+	return "", errors.New("OIDC 模式只能验证令牌，不能签发令牌")
+}
+
+func (v *oidcVerifier) Verify(tokenString string) (*Claims, error) {
+	options := []jwt.ParserOption{
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}),
+	}
+
+	mapClaims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.publicKey(context.Background(), kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("解析令牌失败: %w", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("令牌无效")
+	}
+
+	return mapOIDCClaims(mapClaims)
+}
+
+// publicKey 按 kid 查找公钥，缓存过期或遇到未知 kid 时重新拉取 JWKS
+func (v *oidcVerifier) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, found := v.lookup(kid)
+	stale := time.Since(v.fetchedAt) > v.refreshInterval
+	canRefetch := time.Since(v.fetchedAt) > v.minRefetchInterval
+	v.mu.RUnlock()
+
+	if found && !stale {
+		return key, nil
+	}
+	if !found && !stale && !canRefetch {
+		return nil, fmt.Errorf("未知的密钥: %s", kid)
+	}
+
+	if err := v.refresh(ctx); err != nil {
+		if found {
+			// 刷新失败时继续使用缓存的公钥
+			return key, nil
+		}
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if key, found := v.lookup(kid); found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未知的密钥: %s", kid)
+}
+
+// lookup 调用方需持有读锁。令牌未携带 kid 且 JWKS 只有一个公钥时直接使用该公钥
+func (v *oidcVerifier) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// refresh 拉取身份提供方的 JWKS
+func (v *oidcVerifier) refresh(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// 并发请求可能已完成刷新
+	if time.Since(v.fetchedAt) <= v.minRefetchInterval && v.keys != nil {
+		return nil
+	}
+
+	if v.jwksURI == "" {
+		var discovery oidcDiscovery
+		discoveryURL := strings.TrimSuffix(v.issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, discoveryURL, &discovery); err != nil {
+			return fmt.Errorf("获取 OIDC 发现文档失败: %w", err)
+		}
+		// OIDC Discovery 规范要求发现文档中的 issuer 与配置完全一致
+		if discovery.Issuer != v.issuer {
+			return fmt.Errorf("OIDC 发现文档 issuer 不匹配: %s", discovery.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("OIDC 发现文档缺少 jwks_uri")
+		}
+		v.jwksURI = discovery.JWKSURI
+	}
+
+	var set JWKSet
+	if err := v.getJSON(ctx, v.jwksURI, &set); err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// 跳过不支持的密钥类型，不影响其他密钥
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("JWKS 中没有可用的签名公钥")
+	}
+
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	return nil
+}
+
+func (v *oidcVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求 %s 返回状态码 %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// mapOIDCClaims 将身份提供方的声明映射为本服务的 Claims
+// 优先使用 Casdoor 风格的字段（id、displayName、avatar、phone），其次使用标准 OIDC 字段
+// 用户标识加上 externalUserIDPrefix 前缀，不会与本地用户ID重合
+func mapOIDCClaims(mc jwt.MapClaims) (*Claims, error) {
+	userInfo := &UserInfo{
+		Id:          firstClaim(mc, "id", "sub"),
+		DisplayName: firstClaim(mc, "displayName", "name", "preferred_username"),
+		Avatar:      firstClaim(mc, "avatar", "picture"),
+		Email:       firstClaim(mc, "email"),
+		Phone:       firstClaim(mc, "phone", "phone_number"),
+	}
+	if userInfo.Id == "" {
+		return nil, errors.New("令牌缺少用户标识")
+	}
+	userInfo.Id = externalUserIDPrefix + userInfo.Id
+
+	claims := &Claims{
+		UserInfo:  userInfo,
+		TokenType: TokenTypeAccess,
+	}
+	claims.ID = firstClaim(mc, "jti")
+	claims.Subject = firstClaim(mc, "sub")
+	claims.Issuer = firstClaim(mc, "iss")
+	claims.Audience, _ = mc.GetAudience()
+	claims.ExpiresAt, _ = mc.GetExpirationTime()
+	claims.IssuedAt, _ = mc.GetIssuedAt()
+	claims.NotBefore, _ = mc.GetNotBefore()
+
+	return claims, nil
+}
+
+func firstClaim(mc jwt.MapClaims, names ...string) string {
+	for _, name := range names {
+		if value, ok := mc[name].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// tokenIssuer 在不验证签名的情况下读取令牌的 iss，用于选择验证器
+func tokenIssuer(tokenString string) string {
+	mc := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, mc); err != nil {
+		return ""
+	}
+	issuer, _ := mc.GetIssuer()
+	return issuer
+}
@@ -0,0 +1,200 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mygoframe/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeOIDCProvider 本地的 OIDC 身份提供方替身，提供发现文档和 JWKS
+type fakeOIDCProvider struct {
+	server *httptest.Server
+
+	mu   sync.Mutex
+	kid  string
+	key  *rsa.PrivateKey
+	hits int
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+
+	p := &fakeOIDCProvider{}
+	p.rotate(t, "k1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL,
+			"jwks_uri": p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.hits++
+		jwk, err := NewJWK(&p.key.PublicKey, p.kid, "RS256")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(JWKSet{Keys: []JWK{jwk}})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+// rotate 更换身份提供方的签名密钥
+func (p *fakeOIDCProvider) rotate(t *testing.T, kid string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+	p.mu.Lock()
+	p.kid, p.key = kid, key
+	p.mu.Unlock()
+}
+
+func (p *fakeOIDCProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	return signed
+}
+
+func (p *fakeOIDCProvider) claims(overrides jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":     p.server.URL,
+		"aud":     "my-client",
+		"sub":     "user-123",
+		"name":    "张三",
+		"email":   "zhangsan@example.com",
+		"picture": "https://example.com/a.png",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+	for k, v := range overrides {
+		claims[k] = v
+	}
+	return claims
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	verifier, err := newOIDCVerifier(config.OIDC{
+		Enabled:  true,
+		Issuer:   provider.server.URL,
+		Audience: "my-client",
+	})
+	if err != nil {
+		t.Fatalf("创建 OIDC 验证器失败: %v", err)
+	}
+
+	claims, err := verifier.Verify(provider.sign(t, provider.claims(nil)))
+	if err != nil {
+		t.Fatalf("验证令牌失败: %v", err)
+	}
+	if claims.UserInfo.Id != "oidc:user-123" || claims.UserInfo.DisplayName != "张三" ||
+		claims.UserInfo.Email != "zhangsan@example.com" || claims.UserInfo.Avatar != "https://example.com/a.png" {
+		t.Errorf("声明映射不正确: %+v", claims.UserInfo)
+	}
+	if claims.TokenType != TokenTypeAccess {
+		t.Errorf("外部令牌应视为访问令牌, 实际: %s", claims.TokenType)
+	}
+
+	invalid := map[string]jwt.MapClaims{
+		"错误的 aud": {"aud": "other-client"},
+		"缺少 aud":  {"aud": nil},
+		"错误的 iss": {"iss": "https://evil.example.com"},
+		"已过期":     {"exp": time.Now().Add(-time.Minute).Unix()},
+		"缺少 exp":  {"exp": nil},
+		"缺少用户标识":  {"sub": nil},
+	}
+	for name, overrides := range invalid {
+		claims := provider.claims(overrides)
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+			}
+		}
+		if _, err := verifier.Verify(provider.sign(t, claims)); err == nil {
+			t.Errorf("%s: 令牌应验证失败", name)
+		}
+	}
+}
+
+func TestOIDCVerifierCasdoorClaims(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	verifier, err := newOIDCVerifier(config.OIDC{Enabled: true, Issuer: provider.server.URL, Audience: "my-client"})
+	if err != nil {
+		t.Fatalf("创建 OIDC 验证器失败: %v", err)
+	}
+
+	claims, err := verifier.Verify(provider.sign(t, provider.claims(jwt.MapClaims{
+		"id":          "casdoor-uuid",
+		"displayName": "李四",
+		"avatar":      "https://casdoor/avatar.png",
+		"phone":       "13800000000",
+	})))
+	if err != nil {
+		t.Fatalf("验证令牌失败: %v", err)
+	}
+	if claims.UserInfo.Id != "oidc:casdoor-uuid" || claims.UserInfo.DisplayName != "李四" ||
+		claims.UserInfo.Avatar != "https://casdoor/avatar.png" || claims.UserInfo.Phone != "13800000000" {
+		t.Errorf("Casdoor 声明映射不正确: %+v", claims.UserInfo)
+	}
+}
+
+func TestOIDCVerifierKeyRotation(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+	verifier, err := newOIDCVerifier(config.OIDC{Enabled: true, Issuer: provider.server.URL, Audience: "my-client"})
+	if err != nil {
+		t.Fatalf("创建 OIDC 验证器失败: %v", err)
+	}
+	verifier.minRefetchInterval = 0
+
+	if _, err := verifier.Verify(provider.sign(t, provider.claims(nil))); err != nil {
+		t.Fatalf("验证令牌失败: %v", err)
+	}
+	if _, err := verifier.Verify(provider.sign(t, provider.claims(nil))); err != nil {
+		t.Fatalf("验证令牌失败: %v", err)
+	}
+	if provider.hits != 1 {
+		t.Errorf("公钥应被缓存, JWKS 请求次数: %d", provider.hits)
+	}
+
+	// 身份提供方轮换密钥后，未知 kid 触发重新拉取
+	provider.rotate(t, "k2")
+	if _, err := verifier.Verify(provider.sign(t, provider.claims(nil))); err != nil {
+		t.Fatalf("密钥轮换后验证令牌失败: %v", err)
+	}
+	if provider.hits != 2 {
+		t.Errorf("未知 kid 应触发重新拉取, JWKS 请求次数: %d", provider.hits)
+	}
+}
+
+func TestOIDCVerifierRequiresAudience(t *testing.T) {
+	if _, err := newOIDCVerifier(config.OIDC{Enabled: true, Issuer: "https://idp.example.com"}); err == nil {
+		t.Error("未配置 audience 时应创建失败")
+	}
+}
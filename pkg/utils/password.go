@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"mygoframe/pkg/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的密码哈希算法
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrUnknownPasswordHash 无法识别的密码哈希格式
+var ErrUnknownPasswordHash = errors.New("无法识别的密码哈希格式")
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // 内存开销（KiB）
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// PasswordHasher 密码哈希器，新密码使用配置的算法，验证时根据哈希自身的格式识别算法
+// argon2id 哈希使用 PHC 字符串格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type PasswordHasher struct {
+	algorithm  string
+	argon2     Argon2Params
+	bcryptCost int
+}
+
+// NewPasswordHasher 根据配置创建密码哈希器，未配置的参数使用默认值
+func NewPasswordHasher(cfg config.Password) (*PasswordHasher, error) {
+	h := &PasswordHasher{
+		algorithm: cfg.Algorithm,
+		argon2: Argon2Params{
+			Memory:      cfg.Argon2.Memory,
+			Iterations:  cfg.Argon2.Iterations,
+			Parallelism: cfg.Argon2.Parallelism,
+			SaltLength:  cfg.Argon2.SaltLength,
+			KeyLength:   cfg.Argon2.KeyLength,
+		},
+		bcryptCost: cfg.BcryptCost,
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = PasswordAlgorithmArgon2id
+	}
+	if h.algorithm != PasswordAlgorithmArgon2id && h.algorithm != PasswordAlgorithmBcrypt {
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", h.algorithm)
+	}
+	if h.argon2.Memory == 0 {
+		h.argon2.Memory = 64 * 1024
+	}
+	if h.argon2.Iterations == 0 {
+		h.argon2.Iterations = 3
+	}
+	if h.argon2.Parallelism == 0 {
+		h.argon2.Parallelism = 2
+	}
+	if h.argon2.SaltLength == 0 {
+		h.argon2.SaltLength = 16
+	}
+	if h.argon2.KeyLength == 0 {
+		h.argon2.KeyLength = 32
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost 必须在 %d 到 %d 之间", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	return h, nil
+}
+
+// Hash 使用配置的算法计算密码哈希
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.algorithm == PasswordAlgorithmBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("计算密码哈希失败: %w", err)
+		}
+		return string(hashed), nil
+	}
+
+	salt := make([]byte, h.argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成盐值失败: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2.Iterations, h.argon2.Memory, h.argon2.Parallelism, h.argon2.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.argon2.Memory, h.argon2.Iterations, h.argon2.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify 验证密码是否与哈希匹配，哈希格式无法识别时返回错误
+func (h *PasswordHasher) Verify(password, encoded string) (bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false, err
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(actual, key) == 1, nil
+	case isBcryptHash(encoded):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	default:
+		return false, ErrUnknownPasswordHash
+	}
+}
+
+// NeedsRehash 判断哈希是否使用了与当前配置不同的算法或更弱的参数
+func (h *PasswordHasher) NeedsRehash(encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		if h.algorithm != PasswordAlgorithmArgon2id {
+			return true
+		}
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return true
+		}
+		return params.Memory < h.argon2.Memory ||
+			params.Iterations < h.argon2.Iterations ||
+			params.Parallelism < h.argon2.Parallelism ||
+			uint32(len(salt)) < h.argon2.SaltLength ||
+			uint32(len(key)) < h.argon2.KeyLength
+	case isBcryptHash(encoded):
+		if h.algorithm != PasswordAlgorithmBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < h.bcryptCost
+	default:
+		return true
+	}
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("不支持的 argon2 版本: %s", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("argon2 参数格式错误: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("argon2 盐值格式错误: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("argon2 哈希格式错误: %w", err)
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// isBcryptHash 判断是否为 bcrypt 哈希（$2a$、$2b$、$2y$）
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// PasswordPolicy 密码强度策略
+type PasswordPolicy struct {
+	minLength     int
+	requireUpper  bool
+	requireLower  bool
+	requireDigit  bool
+	requireSymbol bool
+	breached      map[string]struct{}
+}
+
+// NewPasswordPolicy 根据配置创建密码强度策略，配置了泄露密码列表时从文件加载
+// 列表文件每行一个密码，忽略空行和以 # 开头的注释行，比较时不区分大小写
+func NewPasswordPolicy(cfg config.PasswordPolicy) (*PasswordPolicy, error) {
+	p := &PasswordPolicy{
+		minLength:     cfg.MinLength,
+		requireUpper:  cfg.RequireUpper,
+		requireLower:  cfg.RequireLower,
+		requireDigit:  cfg.RequireDigit,
+		requireSymbol: cfg.RequireSymbol,
+	}
+	if cfg.BreachedList == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(cfg.BreachedList)
+	if err != nil {
+		return p, fmt.Errorf("读取泄露密码列表失败: %w", err)
+	}
+	defer f.Close()
+
+	p.breached = make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return p, fmt.Errorf("读取泄露密码列表失败: %w", err)
+	}
+
+	return p, nil
+}
+
+// Validate 检查密码是否满足强度策略
+func (p *PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("密码长度不能少于 %d 位", p.minLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	if p.requireUpper && !hasUpper {
+		return errors.New("密码必须包含大写字母")
+	}
+	if p.requireLower && !hasLower {
+		return errors.New("密码必须包含小写字母")
+	}
+	if p.requireDigit && !hasDigit {
+		return errors.New("密码必须包含数字")
+	}
+	if p.requireSymbol && !hasSymbol {
+		return errors.New("密码必须包含特殊字符")
+	}
+
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return errors.New("该密码已出现在泄露密码库中，请更换密码")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mygoframe/pkg/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 测试使用较小的 argon2 参数以加快运行
+var testArgon2 = config.Argon2{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	h, err := NewPasswordHasher(config.Password{Algorithm: "argon2id", Argon2: testArgon2})
+	if err != nil {
+		t.Fatalf("创建哈希器失败: %v", err)
+	}
+
+	encoded, err := h.Hash("s3cret-pass")
+	if err != nil {
+		t.Fatalf("计算哈希失败: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("哈希应为 PHC 格式: %s", encoded)
+	}
+
+	if ok, err := h.Verify("s3cret-pass", encoded); err != nil || !ok {
+		t.Errorf("正确的密码应验证通过: %v", err)
+	}
+	if ok, _ := h.Verify("wrong-pass", encoded); ok {
+		t.Error("错误的密码不应验证通过")
+	}
+	if h.NeedsRehash(encoded) {
+		t.Error("参数相同时不需要重新哈希")
+	}
+
+	stronger, _ := NewPasswordHasher(config.Password{Algorithm: "argon2id", Argon2: config.Argon2{Memory: 2048, Iterations: 1, Parallelism: 1}})
+	if !stronger.NeedsRehash(encoded) {
+		t.Error("提高参数后旧哈希应重新计算")
+	}
+}
+
+func TestPasswordHasherUpgradesBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("s3cret-pass"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("生成 bcrypt 哈希失败: %v", err)
+	}
+
+	h, _ := NewPasswordHasher(config.Password{Algorithm: "argon2id", Argon2: testArgon2})
+	if ok, err := h.Verify("s3cret-pass", string(legacy)); err != nil || !ok {
+		t.Errorf("应能验证已有的 bcrypt 哈希: %v", err)
+	}
+	if !h.NeedsRehash(string(legacy)) {
+		t.Error("配置为 argon2id 时 bcrypt 哈希应重新计算")
+	}
+
+	b, _ := NewPasswordHasher(config.Password{Algorithm: "bcrypt", BcryptCost: bcrypt.MinCost + 1})
+	if !b.NeedsRehash(string(legacy)) {
+		t.Error("bcrypt 代价因子低于配置时应重新计算")
+	}
+
+	if _, err := h.Verify("s3cret-pass", "plaintext"); err == nil {
+		t.Error("无法识别的哈希格式应返回错误")
+	}
+	if _, err := NewPasswordHasher(config.Password{Algorithm: "md5"}); err == nil {
+		t.Error("不支持的算法应创建失败")
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(list, []byte("# comment\nPassword123\n"), 0o644); err != nil {
+		t.Fatalf("写入泄露密码列表失败: %v", err)
+	}
+
+	p, err := NewPasswordPolicy(config.PasswordPolicy{
+		MinLength:    8,
+		RequireLower: true,
+		RequireDigit: true,
+		BreachedList: list,
+	})
+	if err != nil {
+		t.Fatalf("创建密码策略失败: %v", err)
+	}
+
+	cases := map[string]bool{
+		"abc123":        false, // 长度不足
+		"abcdefgh":      false, // 缺少数字
+		"12345678":      false, // 缺少小写字母
+		"password123":   false, // 在泄露密码列表中（不区分大小写）
+		"correct7horse": true,
+		"长密码也可以abc1234": true,
+	}
+	for password, valid := range cases {
+		if err := p.Validate(password); (err == nil) != valid {
+			t.Errorf("Validate(%q) = %v, 期望通过: %v", password, err, valid)
+		}
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// 业务错误码，用于区分同一 HTTP 状态码下的不同错误，未列出的错误码与 HTTP 状态码相同
+const (
+	CodeEmailNotVerified = 40301 // 邮箱尚未验证
+)
+
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
@@ -36,6 +41,15 @@ func Error(c *gin.Context, message string, status int) {
 	})
 }
 
+// ErrorWithCode 返回带业务错误码的错误响应
+func ErrorWithCode(c *gin.Context, status int, code int, message string) {
+	c.JSON(status, Response{
+		Code:    code,
+		Message: message,
+		Data:    nil,
+	})
+}
+
 func BadRequest(c *gin.Context, message string) {
 	Error(c, message, http.StatusBadRequest)
 }
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"mygoframe/internal/handlers"
+	"mygoframe/internal/models"
+	"mygoframe/routes/middleware"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetupAdminRoutes 设置管理后台路由，仅管理员可访问
+func SetupAdminRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	roleHandler := handlers.NewRoleHandler(db)
+	adminUserHandler := handlers.NewAdminUserHandler(db)
+	auditHandler := handlers.NewAuditHandler(db)
+
+	admin := router.Group("/admin")
+	admin.Use(middleware.JWTAuth(), middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/roles", roleHandler.ListRoles)
+		admin.POST("/roles", roleHandler.CreateRole)
+		admin.PUT("/roles/:id/permissions", roleHandler.SetRolePermissions)
+
+		admin.GET("/users", adminUserHandler.ListUsers)
+		admin.GET("/users/:id", adminUserHandler.GetUser)
+		admin.DELETE("/users/:id", adminUserHandler.DeleteUser)                 // 软删除
+		admin.POST("/users/:id/restore", adminUserHandler.RestoreUser)          // 恢复已删除的用户
+		admin.POST("/users/:id/disable", adminUserHandler.DisableUser)          // 禁用并吊销全部会话
+		admin.POST("/users/:id/enable", adminUserHandler.EnableUser)            // 启用
+		admin.POST("/users/:id/reset-password", adminUserHandler.ResetPassword) // 强制重置密码
+		admin.POST("/users/:id/logout", adminUserHandler.ForceLogout)           // 强制退出所有设备
+
+		admin.GET("/users/:id/roles", roleHandler.GetUserRoles)
+		admin.POST("/users/:id/roles", roleHandler.AssignRole)
+		admin.DELETE("/users/:id/roles/:role", roleHandler.RevokeRole)
+
+		admin.GET("/audit-events", auditHandler.ListEvents)
+		admin.GET("/audit-events/export", auditHandler.ExportEvents) // CSV 导出
+	}
+}
@@ -1,15 +1,35 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"strings"
+	"time"
 
+	"mygoframe/internal/models"
+	"mygoframe/pkg/cache"
 	"mygoframe/pkg/logger"
 	"mygoframe/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 )
 
+// AuditRecorder 记录安全审计事件，由 services.RecordAudit 实现
+type AuditRecorder func(ctx context.Context, event models.AuditEvent)
+
+// auditRecorder JWTAuth 拒绝令牌时使用的审计记录器，未设置时不记录
+var auditRecorder AuditRecorder
+
+// invalidTokenAuditInterval 同一 IP 提交无法解析的令牌时，每个间隔内最多记录一次审计事件
+const invalidTokenAuditInterval = time.Minute
+
+// SetAuditRecorder 设置认证中间件的审计记录器，在启动时调用
+func SetAuditRecorder(recorder AuditRecorder) {
+	auditRecorder = recorder
+}
+
 // JWTAuth JWT认证中间件
 func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -41,11 +61,36 @@ func JWTAuth() gin.HandlerFunc {
 		// 解析令牌
 		claims, err := jwtUtil.ParseToken(parts[1])
 		if err != nil {
+			// 过期是访问令牌的正常生命周期，不记入审计日志
+			if !errors.Is(err, jwt.ErrTokenExpired) {
+				auditInvalidToken(c)
+			}
 			utils.Unauthorized(c, "Access token is invalid or expired: "+err.Error())
 			c.Abort()
 			return
 		}
 
+		// 只接受访问令牌，刷新令牌不能用于访问接口
+		if claims.TokenType != utils.TokenTypeAccess {
+			auditTokenRejected(c, claimsUserID(claims), "invalid token type: "+claims.TokenType)
+			utils.Unauthorized(c, "Invalid token type")
+			c.Abort()
+			return
+		}
+
+		// 检查令牌是否已被吊销（登出或退出所有设备）
+		if err := jwtUtil.CheckRevoked(c, claims); err != nil {
+			if errors.Is(err, utils.ErrTokenRevoked) {
+				auditTokenRejected(c, claimsUserID(claims), "revoked token")
+				utils.Unauthorized(c, "Access token has been revoked")
+			} else {
+				logger.Error("查询令牌吊销状态失败", zap.Error(err))
+				utils.ServerError(c, "Authentication service error")
+			}
+			c.Abort()
+			return
+		}
+
 		// 验证用户ID
 		if claims.UserInfo.Id == "" {
 			utils.Unauthorized(c, "Invalid user information")
@@ -54,12 +99,7 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		// 将用户信息存储到上下文
-		c.Set("user", claims.UserInfo)
-		//c.Set("display_name", claims.UserInfo.DisplayName)
-		//c.Set("avatar", claims.UserInfo.Avatar)
-		//c.Set("email", claims.UserInfo.Email)
-		//c.Set("phone", claims.UserInfo.Phone)
-		//c.Set("claims", claims)
+		setCurrentUser(c, claims)
 
 		// 继续处理请求
 		c.Next()
@@ -88,19 +128,14 @@ func OptionalJWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtUtil.ParseToken(parts[1])
+		claims, err := jwtUtil.ValidateToken(c, parts[1], utils.TokenTypeAccess)
 		if err != nil {
 			c.Next()
 			return
 		}
 
 		if claims.UserInfo.Id != "" {
-			c.Set("user_id", claims.UserInfo.Id)
-			c.Set("display_name", claims.UserInfo.DisplayName)
-			c.Set("avatar", claims.UserInfo.Avatar)
-			c.Set("email", claims.UserInfo.Email)
-			c.Set("phone", claims.UserInfo.Phone)
-			c.Set("claims", claims)
+			setCurrentUser(c, claims)
 		}
 
 		c.Next()
@@ -143,34 +178,48 @@ func GetCurrentClaims(c *gin.Context) (*utils.Claims, bool) {
 	return claims, true
 }
 
-// RequireRole 角色权限检查中间件（示例）
-func RequireRole(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		_, exists := GetCurrentClaims(c)
-		if !exists {
-			utils.Unauthorized(c, "User information does not exist")
-			c.Abort()
-			return
-		}
-
-		// 这里可以根据需要扩展角色验证逻辑
-		// 例如从数据库获取用户角色进行验证
-		userRole := "user" // 示例角色
-
-		hasRole := false
-		for _, role := range roles {
-			if userRole == role {
-				hasRole = true
-				break
-			}
-		}
+// auditTokenRejected 记录令牌被拒绝的审计事件，用于发现伪造令牌或被盗令牌的使用
+func auditTokenRejected(c *gin.Context, userID string, reason string) {
+	if auditRecorder == nil {
+		return
+	}
+	auditRecorder(c, models.AuditEvent{
+		Type:     models.AuditEventTokenRejected,
+		Outcome:  models.AuditOutcomeFailure,
+		TargetID: userID,
+		Detail:   reason,
+	})
+}
 
-		if !hasRole {
-			utils.Forbidden(c, "Insufficient permissions")
-			c.Abort()
-			return
-		}
+// auditInvalidToken 记录无法解析或签名无效的令牌
+// 任何人都能构造这类令牌，按 IP 限制记录频率，避免大量垃圾令牌造成无限制的审计写入
+// 签名有效但已吊销或类型不符的令牌每次都会记录
+func auditInvalidToken(c *gin.Context) {
+	if auditRecorder == nil {
+		return
+	}
+	added, err := cache.Add(c, "audit:invalid_token:"+c.ClientIP(), "1", invalidTokenAuditInterval)
+	if err != nil || !added {
+		return
+	}
+	auditTokenRejected(c, "", "invalid token")
+}
 
-		c.Next()
+// claimsUserID 令牌声明中的用户 ID，声明不含用户信息时返回空字符串
+func claimsUserID(claims *utils.Claims) string {
+	if claims.UserInfo == nil {
+		return ""
 	}
+	return claims.UserInfo.Id
+}
+
+// setCurrentUser 将令牌中的用户信息写入上下文，JWTAuth 与 OptionalJWTAuth 保持一致
+func setCurrentUser(c *gin.Context, claims *utils.Claims) {
+	c.Set("user", claims.UserInfo)
+	c.Set("user_id", claims.UserInfo.Id)
+	c.Set("display_name", claims.UserInfo.DisplayName)
+	c.Set("avatar", claims.UserInfo.Avatar)
+	c.Set("email", claims.UserInfo.Email)
+	c.Set("phone", claims.UserInfo.Phone)
+	c.Set("claims", claims)
 }
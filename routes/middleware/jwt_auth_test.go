@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mygoframe/internal/models"
+	"mygoframe/pkg/cache"
+	"mygoframe/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditInvalidTokenLimitedPerIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := cache.Init(&config.Config{
+		LocalCache: config.LocalCache{MaxCost: 1 << 20, MaxKeys: 1e4},
+	}); err != nil {
+		t.Fatalf("初始化缓存失败: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+
+	var events []models.AuditEvent
+	SetAuditRecorder(func(ctx context.Context, event models.AuditEvent) {
+		events = append(events, event)
+	})
+	t.Cleanup(func() { SetAuditRecorder(nil) })
+
+	reject := func(remoteAddr string) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.RemoteAddr = remoteAddr
+		auditInvalidToken(c)
+	}
+
+	for i := 0; i < 10; i++ {
+		reject("203.0.113.7:40000")
+	}
+	if len(events) != 1 {
+		t.Errorf("同一 IP 的无效令牌记录了 %d 次, 期望 1", len(events))
+	}
+	reject("203.0.113.8:40000")
+	if len(events) != 2 {
+		t.Errorf("其他 IP 的无效令牌应单独记录, 实际共 %d 次", len(events))
+	}
+}
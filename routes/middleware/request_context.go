@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"mygoframe/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader 请求 ID 请求头，上游网关已生成时沿用，便于串联日志
+const requestIDHeader = "X-Request-ID"
+
+// RequestContext 请求上下文中间件
+// 生成请求 ID 并记录客户端 IP 和 User-Agent，供审计日志等服务层逻辑读取
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(utils.ContextKeyRequestID, requestID)
+		c.Set(utils.ContextKeyClientIP, c.ClientIP())
+		c.Set(utils.ContextKeyUserAgent, c.Request.UserAgent())
+		c.Header(requestIDHeader, requestID)
+
+		c.Next()
+	}
+}
@@ -2,6 +2,9 @@ package routes
 
 import (
 	"mygoframe/internal/handlers"
+	"mygoframe/internal/models"
+	"mygoframe/internal/services"
+	"mygoframe/routes/middleware"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,9 +14,18 @@ import (
 func InitNewsRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	newsHandler := handlers.NewNewsHandler(db)
 
+	// 可选登录：登录用户可以看到授权策略允许的内部快讯
 	newsGroup := router.Group("/news")
+	newsGroup.Use(middleware.OptionalJWTAuth())
 	{
 		newsGroup.GET("", newsHandler.GetNewsList)
 		newsGroup.GET("/:id", newsHandler.GetNewsByID)
 	}
+
+	protected := router.Group("/news")
+	protected.Use(middleware.JWTOrAPIKeyAuth(services.NewAPIKeyService(db)), middleware.RequireScope(models.ScopeNewsWrite))
+	{
+		protected.POST("", middleware.RequirePolicy("news:create", "news"), newsHandler.CreateNews)
+		protected.PUT("/:id", newsHandler.UpdateNews) // 作者校验在服务层完成
+	}
 }
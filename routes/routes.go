@@ -1,23 +1,36 @@
 package routes
 
 import (
+	"fmt"
 	"mygoframe/pkg/config"
+	"mygoframe/pkg/storage"
 	"mygoframe/routes/middleware"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(db *gorm.DB) *gin.Engine {
+func SetupRoutes(db *gorm.DB) (*gin.Engine, error) {
 	if config.GetBuildMode() == "pro" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.New()
-	r.Use(middleware.Logger()) // 请求日志
+	r, err := newEngine(config.GetConfig().System)
+	if err != nil {
+		return nil, err
+	}
+	r.Use(middleware.RequestContext()) // 请求 ID 与客户端信息
+	r.Use(middleware.Logger())         // 请求日志
 	r.Use(middleware.Cors())
 	r.Use(middleware.Recovery())
 
+	SetupWellKnownRoutes(r)
+
+	// 本地存储的上传文件（头像等）通过静态路由访问
+	if local, ok := storage.Get().(*storage.LocalStorage); ok {
+		r.Static(local.URLPrefix(), local.Root())
+	}
+
 	apiGroup := r.Group("/api")
 	{
 		apiGroup.GET("/health", func(c *gin.Context) {
@@ -26,11 +39,22 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 
 		InitNewsRoutes(apiGroup, db)
 		SetupUserRoutes(apiGroup, db)
+		SetupAdminRoutes(apiGroup, db)
 	}
 
 	r.NoRoute(func(c *gin.Context) {
 		c.JSON(404, gin.H{"code": 404, "message": "Not Found", "data": nil})
 	})
 
-	return r
+	return r, nil
+}
+
+// newEngine 创建路由引擎，只信任配置的反向代理传入的客户端 IP
+// gin 默认信任所有代理，客户端可通过 X-Forwarded-For 伪造任意 IP
+func newEngine(cfg config.System) (*gin.Engine, error) {
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("可信代理配置无效: %w", err)
+	}
+	return r, nil
 }
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mygoframe/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newClientIPCounter 创建按客户端 IP 计数的路由，与登录限制按 IP 统计失败次数的方式一致
+func newClientIPCounter(t *testing.T, trustedProxies []string) (*gin.Engine, map[string]int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r, err := newEngine(config.System{TrustedProxies: trustedProxies})
+	if err != nil {
+		t.Fatalf("创建路由失败: %v", err)
+	}
+	counts := make(map[string]int)
+	r.POST("/login", func(c *gin.Context) {
+		counts[c.ClientIP()]++
+		c.Status(http.StatusUnauthorized)
+	})
+	return r, counts
+}
+
+func login(r *gin.Engine, remoteAddr string, forwardedFor string) {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestSpoofedForwardedForDoesNotResetIPCounter(t *testing.T) {
+	r, counts := newClientIPCounter(t, nil)
+
+	for i := 0; i < 5; i++ {
+		login(r, "203.0.113.7:40000", fmt.Sprintf("198.51.100.%d", i))
+	}
+
+	if counts["203.0.113.7"] != 5 {
+		t.Errorf("按连接地址统计的次数 = %d, 期望 5（全部计数: %v）", counts["203.0.113.7"], counts)
+	}
+	if len(counts) != 1 {
+		t.Errorf("伪造的 X-Forwarded-For 不应产生新的计数: %v", counts)
+	}
+}
+
+func TestTrustedProxyForwardedFor(t *testing.T) {
+	r, counts := newClientIPCounter(t, []string{"10.0.0.0/8"})
+
+	// 来自可信代理的请求使用代理传入的客户端 IP
+	login(r, "10.0.0.2:40000", "198.51.100.1")
+	if counts["198.51.100.1"] != 1 {
+		t.Errorf("可信代理转发的客户端 IP 未生效: %v", counts)
+	}
+
+	// 不经过可信代理的请求忽略 X-Forwarded-For
+	login(r, "203.0.113.7:40000", "198.51.100.2")
+	if counts["203.0.113.7"] != 1 || counts["198.51.100.2"] != 0 {
+		t.Errorf("非可信来源的 X-Forwarded-For 不应生效: %v", counts)
+	}
+}
+
+func TestInvalidTrustedProxies(t *testing.T) {
+	if _, err := newEngine(config.System{TrustedProxies: []string{"not-an-ip"}}); err == nil {
+		t.Error("无效的可信代理配置应返回错误")
+	}
+}
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"mygoframe/internal/handlers"
+	"mygoframe/internal/models"
+	"mygoframe/internal/services"
 	"mygoframe/routes/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -11,16 +13,27 @@ import (
 // SetupUserRoutes 设置用户相关路由
 func SetupUserRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	userHandler := handlers.NewUserHandler(db)
+	apiKeyService := services.NewAPIKeyService(db)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	mfaHandler := handlers.NewMFAHandler(db)
+	sessionHandler := handlers.NewSessionHandler(db)
 
 	public := router.Group("/users")
 	{
 		public.POST("/register", userHandler.Register)
+		public.GET("/verify-email", userHandler.VerifyEmail)  // 打开邮件中的验证链接
+		public.POST("/verify-email", userHandler.VerifyEmail) // 提交邮箱验证令牌
 		public.POST("/login", userHandler.Login)
+		public.POST("/login/2fa", userHandler.LoginWith2FA)     // 两步验证登录
+		public.POST("/login/sms", userHandler.LoginWithSMS)     // 短信验证码登录
+		public.POST("/login/email", userHandler.LoginWithEmail) // 邮箱验证码登录
 		public.POST("/refresh-token", userHandler.RefreshToken)
 		public.POST("/send-sms-code", userHandler.SendSMSCode)         // 发送短信验证码
 		public.POST("/verify-sms-code", userHandler.VerifySMSCode)     // 验证短信验证码
 		public.POST("/send-email-code", userHandler.SendEmailCode)     // 发送邮箱验证码
 		public.POST("/verify-email-code", userHandler.VerifyEmailCode) // 验证邮箱验证码
+		public.POST("/password/forgot", userHandler.ForgotPassword)    // 忘记密码
+		public.POST("/password/reset", userHandler.ResetPassword)      // 重置密码
 
 		// 队列测试
 		public.POST("/test_queue", userHandler.TestQueue)
@@ -31,6 +44,33 @@ func SetupUserRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	protected.Use(middleware.JWTAuth())
 	{
 		protected.POST("/logout", userHandler.Logout)
-		protected.GET("/profile", userHandler.GetProfile)
+		protected.POST("/logout-all", userHandler.LogoutAll)           // 退出所有设备
+		protected.POST("/password/change", userHandler.ChangePassword) // 修改密码
+		protected.PATCH("/profile", userHandler.UpdateProfile)         // 更新个人资料
+		protected.POST("/profile/avatar", userHandler.UploadAvatar)    // 上传头像
+
+		// 登录会话（设备）管理
+		protected.GET("/sessions", sessionHandler.ListSessions)
+		protected.DELETE("/sessions/:id", sessionHandler.RevokeSession) // 退出指定设备
+
+		// 两步验证
+		protected.POST("/2fa/enroll", mfaHandler.Enroll)
+		protected.POST("/2fa/confirm", mfaHandler.Confirm)
+		protected.POST("/2fa/disable", mfaHandler.Disable)
+		protected.POST("/2fa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+
+		// API Key 管理只允许通过登录令牌操作
+		protected.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+		protected.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+		protected.GET("/api-keys/:id", apiKeyHandler.GetAPIKey)
+		protected.PATCH("/api-keys/:id", apiKeyHandler.UpdateAPIKey)
+		protected.DELETE("/api-keys/:id", apiKeyHandler.DeleteAPIKey)
+	}
+
+	// 同时接受登录令牌和 API Key
+	principal := router.Group("/users")
+	principal.Use(middleware.JWTOrAPIKeyAuth(apiKeyService))
+	{
+		principal.GET("/profile", middleware.RequireScope(models.ScopeProfileRead), userHandler.GetProfile)
 	}
 }
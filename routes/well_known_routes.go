@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"mygoframe/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupWellKnownRoutes 设置 /.well-known 下的标准发现路由
+func SetupWellKnownRoutes(router *gin.Engine) {
+	jwksHandler := handlers.NewJWKSHandler()
+
+	wellKnown := router.Group("/.well-known")
+	{
+		wellKnown.GET("/jwks.json", jwksHandler.GetJWKS)
+	}
+}